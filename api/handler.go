@@ -0,0 +1,132 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/l3uddz/crop/cache"
+	"strings"
+	"time"
+)
+
+/* Struct */
+
+type Run struct {
+	Command         string    `json:"command"`
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	Files           int       `json:"files"`
+	Bytes           int64     `json:"bytes"`
+	Transfers       int       `json:"transfers"`
+	Deletes         int       `json:"deletes"`
+	Errors          int       `json:"errors"`
+	ServiceAccounts []string  `json:"service_accounts,omitempty"`
+}
+
+type Ban struct {
+	Key     string    `json:"key"`
+	Expires time.Time `json:"expires"`
+}
+
+/* Private */
+
+func (s *Server) listJobs(c *fiber.Ctx) error {
+	jobs := s.c.Jobs()
+
+	// the most recent run of each job, looked up with the cache open once
+	if err := cache.Acquire(); err != nil {
+		return err
+	}
+	defer cache.Release()
+
+	for i := range jobs {
+		r, err := cache.GetLastRun(jobs[i].Scope, jobs[i].Name)
+		if err != nil {
+			return err
+		}
+
+		if r == nil {
+			continue
+		}
+
+		jobs[i].LastRun = &Run{
+			Command:         r.Command,
+			Started:         r.Started,
+			Finished:        r.Finished,
+			Status:          r.Status,
+			Error:           r.Error,
+			Files:           r.Files,
+			Bytes:           r.Bytes,
+			Transfers:       r.Transfers,
+			Deletes:         r.Deletes,
+			Errors:          r.Errors,
+			ServiceAccounts: r.ServiceAccounts,
+		}
+	}
+
+	return c.JSON(jobs)
+}
+
+func (s *Server) runJob(c *fiber.Ctx) error {
+	if err := s.c.RunJob(c.Params("scope"), c.Params("name")); err != nil {
+		return err
+	}
+
+	s.log.Infof("Started %s %q", c.Params("scope"), c.Params("name"))
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": "started"})
+}
+
+func (s *Server) cancelJob(c *fiber.Ctx) error {
+	if err := s.c.CancelJob(c.Params("scope"), c.Params("name")); err != nil {
+		return err
+	}
+
+	s.log.Infof("Cancelled %s %q", c.Params("scope"), c.Params("name"))
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": "cancelling"})
+}
+
+func (s *Server) listBans(c *fiber.Ctx) error {
+	bans, err := cache.GetBans()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]Ban, 0, len(bans))
+	for _, b := range bans {
+		entries = append(entries, Ban{
+			Key:     b.Path,
+			Expires: b.Expires,
+		})
+	}
+
+	return c.JSON(entries)
+}
+
+func (s *Server) clearBan(c *fiber.Ctx) error {
+	// service accounts are banned by their path, so the key is passed as a query parameter
+	key := strings.TrimSuffix(c.Query("key"), ":")
+	if key == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "key is required")
+	}
+
+	cleared, err := cache.ClearBan(key)
+	if err != nil {
+		return err
+	}
+
+	if !cleared {
+		return fiber.NewError(fiber.StatusNotFound, "ban not found")
+	}
+
+	s.log.Infof("Cleared ban: %q", key)
+	return c.JSON(fiber.Map{"cleared": key})
+}
+
+func (s *Server) listServiceAccounts(c *fiber.Ctx) error {
+	capacity, err := s.c.ServiceAccounts()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(capacity)
+}
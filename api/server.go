@@ -0,0 +1,133 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/l3uddz/crop/logger"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+/* Interface */
+
+// Controller is implemented by the daemon, which owns the scheduled uploader(s) & syncer(s).
+type Controller interface {
+	Jobs() []Job
+	RunJob(scope string, name string) error
+	CancelJob(scope string, name string) error
+	ServiceAccounts() ([]rclone.RemoteCapacity, error)
+}
+
+/* Struct */
+
+type Job struct {
+	Scope    string    `json:"scope"`
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	Running  bool      `json:"running"`
+	NextRun  time.Time `json:"next_run"`
+	LastRun  *Run      `json:"last_run"`
+}
+
+type Server struct {
+	Listen string
+
+	app   *fiber.App
+	log   *logrus.Entry
+	token string
+	c     Controller
+}
+
+/* Var */
+
+var (
+	ErrJobNotFound   = errors.New("job not found")
+	ErrJobRunning    = errors.New("job is already running")
+	ErrJobNotRunning = errors.New("job is not running")
+)
+
+/* Public */
+
+func New(listen string, token string, c Controller) *Server {
+	s := &Server{
+		Listen: listen,
+		app: fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+			ErrorHandler:          errorHandler,
+			// the standard library encoder, fiber's bundled encoder depends on go runtime internals
+			JSONEncoder: json.Marshal,
+		}),
+		log:   logger.GetLogger("api"),
+		token: token,
+		c:     c,
+	}
+
+	// middleware(s)
+	s.app.Use(recover.New())
+	s.app.Use(s.authenticate)
+
+	// route(s)
+	r := s.app.Group("/api")
+
+	r.Get("/jobs", s.listJobs)
+	r.Post("/jobs/:scope/:name/run", s.runJob)
+	r.Post("/jobs/:scope/:name/cancel", s.cancelJob)
+
+	r.Get("/bans", s.listBans)
+	r.Delete("/bans", s.clearBan)
+
+	r.Get("/service_accounts", s.listServiceAccounts)
+
+	return s
+}
+
+func (s *Server) Run() {
+	go func() {
+		s.log.Infof("Starting api server: %s", s.Listen)
+
+		if err := s.app.Listen(s.Listen); err != nil {
+			s.log.WithError(err).Error("API server failed...")
+		}
+	}()
+}
+
+func (s *Server) Stop() {
+	if err := s.app.Shutdown(); err != nil {
+		s.log.WithError(err).Error("Failed shutting down api server...")
+	}
+}
+
+/* Private */
+
+func (s *Server) authenticate(c *fiber.Ctx) error {
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+
+	// an empty token would match requests without one
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		s.log.Warnf("Rejected unauthenticated request from %s: %s %s", c.IP(), c.Method(), c.Path())
+		return fiber.ErrUnauthorized
+	}
+
+	return c.Next()
+}
+
+func errorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+
+	var e *fiber.Error
+	switch {
+	case errors.As(err, &e):
+		code = e.Code
+	case errors.Is(err, ErrJobNotFound):
+		code = fiber.StatusNotFound
+	case errors.Is(err, ErrJobRunning), errors.Is(err, ErrJobNotRunning):
+		code = fiber.StatusConflict
+	}
+
+	return c.Status(code).JSON(fiber.Map{"error": err.Error()})
+}
@@ -5,6 +5,9 @@ import (
 	"github.com/l3uddz/crop/stringutils"
 	"github.com/pkg/errors"
 	"github.com/zippoxer/bow"
+	"sync"
+	"syscall"
+	"time"
 )
 
 var (
@@ -12,7 +15,15 @@ var (
 	cacheFilePath string
 
 	// Internal
-	db *bow.DB
+	db     *bow.DB
+	dbOpts []bow.Option
+	dbRefs int
+	dbMtx  sync.Mutex
+)
+
+const (
+	openRetryInterval = 250 * time.Millisecond
+	openTimeout       = 2 * time.Minute
 )
 
 /* Public */
@@ -29,25 +40,76 @@ func Init(cachePath string, logLevel int) error {
 		opts = append(opts, bow.SetLogger(nil))
 	}
 
-	// init database
-	v, err := bow.Open(cachePath, opts...)
-	if err != nil {
-		return errors.WithMessage(err, "failed opening cache")
-	}
+	dbOpts = opts
 
-	db = v
+	// ensure the database can be opened
+	if err := Acquire(); err != nil {
+		return err
+	}
 
+	Release()
 	return nil
 }
 
-func Close() {
-	// clear banned sa's
-	ClearExpiredBans()
+// Acquire opens the cache, unless it is already open within this process.
+// The cache is only held open while in use so that multiple crop processes can share it.
+// Every call to Acquire must be paired with a call to Release. Callers using several keys at once should hold
+// the cache around them, so it is opened once rather than for each key.
+func Acquire() error {
+	dbMtx.Lock()
+	defer dbMtx.Unlock()
+
+	if dbRefs > 0 {
+		dbRefs++
+		return nil
+	}
+
+	timeout := time.Now().Add(openTimeout)
+
+	for {
+		v, err := bow.Open(cacheFilePath, dbOpts...)
+		switch {
+		case err == nil:
+			db = v
+			dbRefs = 1
+			return nil
+		case errors.Is(err, syscall.EWOULDBLOCK) && time.Now().Before(timeout):
+			// another crop process is using the cache
+			log.Tracef("Cache is in use by another process, re-trying in %v...", openRetryInterval)
+			time.Sleep(openRetryInterval)
+		default:
+			return errors.WithMessage(err, "failed opening cache")
+		}
+	}
+}
+
+func Release() {
+	dbMtx.Lock()
+	defer dbMtx.Unlock()
+
+	if dbRefs == 0 {
+		return
+	}
+
+	dbRefs--
+	if dbRefs > 0 {
+		return
+	}
 
 	// close
 	if err := db.Close(); err != nil {
 		log.WithError(err).Error("Failed closing cache gracefully...")
 	}
+
+	db = nil
+}
+
+func Close() {
+	// clear banned sa's
+	ClearExpiredBans()
+
+	// clear usage from previous quota days
+	ClearExpiredUsage()
 }
 
 func ShowUsing() {
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/zippoxer/bow"
+	"strings"
+	"time"
+)
+
+type Run struct {
+	ID              string `bow:"key"`
+	Command         string
+	Scope           string
+	Name            string
+	Started         time.Time
+	Finished        time.Time
+	Status          string
+	Error           string
+	Files           int
+	Bytes           int64
+	Transfers       int
+	Checks          int
+	Deletes         int
+	Renames         int
+	Errors          int
+	ServiceAccounts []string
+}
+
+// RunFilter limits the runs returned by GetRuns, zero values match every run.
+type RunFilter struct {
+	Name    string
+	Command string
+	Status  string
+	Since   time.Time
+	Until   time.Time
+
+	// only the most recent runs (0 = all)
+	Limit int
+}
+
+// lastRun is the most recent run of an uploader / syncer, so it is found without iterating the history.
+type lastRun struct {
+	Job string `bow:"key"`
+	Run Run
+}
+
+func AddRun(r *Run) error {
+	if err := Acquire(); err != nil {
+		return err
+	}
+	defer Release()
+
+	if r.ID == "" {
+		// keys sort by start time
+		r.ID = fmt.Sprintf("%019d/%s/%s/%s", r.Started.UnixNano(), r.Command, r.Scope, r.Name)
+	}
+
+	if err := db.Bucket("history").Put(r); err != nil {
+		return err
+	}
+
+	return db.Bucket("last_run").Put(lastRun{
+		Job: jobKey(r.Scope, r.Name),
+		Run: *r,
+	})
+}
+
+// GetRuns returns the recorded runs matching f, oldest first.
+func GetRuns(f RunFilter) ([]Run, error) {
+	if err := Acquire(); err != nil {
+		return nil, err
+	}
+	defer Release()
+
+	// keys sort by start time
+	iter := db.Bucket("history").Iter()
+	defer iter.Close()
+
+	runs := make([]Run, 0)
+
+	for {
+		var page Run
+		if !iter.Next(&page) {
+			break
+		}
+
+		if !f.Until.IsZero() && page.Started.After(f.Until) {
+			break
+		}
+
+		if !f.matches(&page) {
+			continue
+		}
+
+		runs = append(runs, page)
+
+		// keep the most recent
+		if f.Limit > 0 && len(runs) > f.Limit {
+			runs = runs[1:]
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetLastRun returns the most recent run of an uploader / syncer, or nil when it has not run.
+func GetLastRun(scope string, name string) (*Run, error) {
+	if err := Acquire(); err != nil {
+		return nil, err
+	}
+	defer Release()
+
+	var item lastRun
+	err := db.Bucket("last_run").Get(jobKey(scope, name), &item)
+	switch {
+	case err == bow.ErrNotFound:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return &item.Run, nil
+}
+
+// PruneRuns removes runs that started before t, returning how many were removed.
+func PruneRuns(t time.Time) (int, error) {
+	if err := Acquire(); err != nil {
+		return 0, err
+	}
+	defer Release()
+
+	// keys sort by start time, so only the runs to remove are read
+	ids := make([]string, 0)
+
+	iter := db.Bucket("history").Iter()
+	for {
+		var page Run
+		if !iter.Next(&page) || !page.Started.Before(t) {
+			break
+		}
+
+		ids = append(ids, page.ID)
+	}
+
+	err := iter.Err()
+	iter.Close()
+
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := db.Bucket("history").Delete(id); err != nil {
+			return i, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+/* Private */
+
+func (f RunFilter) matches(r *Run) bool {
+	switch {
+	case f.Name != "" && !strings.EqualFold(r.Name, f.Name):
+		return false
+	case f.Command != "" && !strings.EqualFold(r.Command, f.Command):
+		return false
+	case f.Status != "" && !strings.EqualFold(r.Status, f.Status):
+		return false
+	case !f.Since.IsZero() && r.Started.Before(f.Since):
+		return false
+	default:
+		return true
+	}
+}
+
+func jobKey(scope string, name string) string {
+	return strings.ToLower(scope + "/" + name)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRuns(t *testing.T) {
+	initTestCache(t)
+
+	started := time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)
+	for i, r := range []Run{
+		{Command: "upload", Scope: "uploader", Name: "tv", Status: "success"},
+		{Command: "sync", Scope: "syncer", Name: "movies", Status: "failure"},
+		{Command: "upload", Scope: "uploader", Name: "tv", Status: "failure"},
+		{Command: "upload", Scope: "uploader", Name: "movies", Status: "success"},
+	} {
+		r := r
+		r.Started = started.Add(time.Duration(i) * time.Hour)
+		r.Finished = r.Started.Add(time.Minute)
+
+		if err := AddRun(&r); err != nil {
+			t.Fatalf("AddRun() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		f    RunFilter
+		want []string
+	}{
+		{"all", RunFilter{}, []string{"upload/tv", "sync/movies", "upload/tv", "upload/movies"}},
+		{"name", RunFilter{Name: "TV"}, []string{"upload/tv", "upload/tv"}},
+		{"command & status", RunFilter{Command: "upload", Status: "success"}, []string{"upload/tv", "upload/movies"}},
+		{"since", RunFilter{Since: started.Add(2 * time.Hour)}, []string{"upload/tv", "upload/movies"}},
+		{"until", RunFilter{Until: started.Add(time.Hour)}, []string{"upload/tv", "sync/movies"}},
+		{"most recent", RunFilter{Limit: 1}, []string{"upload/movies"}},
+		{"most recent matching", RunFilter{Name: "tv", Limit: 1}, []string{"upload/tv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, err := GetRuns(tt.f)
+			if err != nil {
+				t.Fatalf("GetRuns() error = %v", err)
+			}
+
+			got := make([]string, 0, len(runs))
+			for _, r := range runs {
+				got = append(got, r.Command+"/"+r.Name)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetRuns() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("GetRuns() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	// last run
+	last, err := GetLastRun("Uploader", "TV")
+	if err != nil || last == nil || last.Status != "failure" {
+		t.Errorf("GetLastRun() = %v, %v, want the failed run", last, err)
+	}
+
+	if last, err := GetLastRun("syncer", "tv"); err != nil || last != nil {
+		t.Errorf("GetLastRun() = %v, %v, want nil", last, err)
+	}
+
+	// prune
+	removed, err := PruneRuns(started.Add(90 * time.Minute))
+	if err != nil || removed != 2 {
+		t.Fatalf("PruneRuns() = %d, %v, want 2", removed, err)
+	}
+
+	if runs, err := GetRuns(RunFilter{}); err != nil || len(runs) != 2 {
+		t.Errorf("GetRuns() after pruning = %d runs, %v, want 2", len(runs), err)
+	}
+}
+
+func initTestCache(t *testing.T) {
+	t.Helper()
+
+	if err := Init(filepath.Join(t.TempDir(), "cache"), 0); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+}
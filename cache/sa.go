@@ -2,6 +2,7 @@ package cache
 
 import (
 	"github.com/zippoxer/bow"
+	"sort"
 	"time"
 )
 
@@ -11,6 +12,12 @@ type Banned struct {
 }
 
 func ClearExpiredBans() {
+	if err := Acquire(); err != nil {
+		log.WithError(err).Error("Failed clearing expired bans")
+		return
+	}
+	defer Release()
+
 	iter := db.Bucket("banned").Iter()
 	defer iter.Close()
 
@@ -21,6 +28,12 @@ func ClearExpiredBans() {
 }
 
 func IsBanned(key string) (bool, time.Time) {
+	if err := Acquire(); err != nil {
+		log.WithError(err).Errorf("Failed checking banned bucket for: %q", key)
+		return false, time.Time{}
+	}
+	defer Release()
+
 	// check if key was found in banned bucket
 	var item Banned
 	err := db.Bucket("banned").Get(key, &item)
@@ -52,11 +65,76 @@ func IsBanned(key string) (bool, time.Time) {
 	return true, item.Expires
 }
 
-func SetBanned(key string, hours int) error {
-	expiry := time.Now().UTC().Add(time.Duration(hours) * time.Hour)
+func SetBanned(key string, expires time.Time) error {
+	if err := Acquire(); err != nil {
+		return err
+	}
+	defer Release()
 
 	return db.Bucket("banned").Put(Banned{
 		Path:    key,
-		Expires: expiry,
+		Expires: expires.UTC(),
+	})
+}
+
+func GetBans() ([]Banned, error) {
+	if err := Acquire(); err != nil {
+		return nil, err
+	}
+	defer Release()
+
+	iter := db.Bucket("banned").Iter()
+	defer iter.Close()
+
+	bans := make([]Banned, 0)
+	now := time.Now().UTC()
+
+	var page Banned
+	for iter.Next(&page) {
+		if page.Expires.Before(now) {
+			// this ban has expired
+			continue
+		}
+
+		bans = append(bans, page)
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Expires.Before(bans[j].Expires)
 	})
+
+	return bans, nil
+}
+
+// ClearBan removes the ban for key, returning false when key was not banned.
+func ClearBan(key string) (bool, error) {
+	if err := Acquire(); err != nil {
+		return false, err
+	}
+	defer Release()
+
+	var item Banned
+	err := db.Bucket("banned").Get(key, &item)
+	switch {
+	case err == bow.ErrNotFound:
+		break
+	case err != nil:
+		return false, err
+	default:
+		if err := db.Bucket("banned").Delete(key); err != nil {
+			return false, err
+		}
+	}
+
+	// forget previous strikes too
+	if err := ClearStrikes(key); err != nil {
+		return false, err
+	}
+
+	// an expired ban was no longer in effect
+	return err == nil && !item.Expires.Before(time.Now().UTC()), nil
 }
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"github.com/zippoxer/bow"
+	"time"
+)
+
+type Selection struct {
+	Key  string `bow:"key"`
+	Path string
+	Time time.Time
+}
+
+func SetSelected(remote string, path string) error {
+	if err := Acquire(); err != nil {
+		return err
+	}
+	defer Release()
+
+	now := time.Now().UTC()
+
+	// remember the last service account issued for this remote
+	if err := db.Bucket("selected").Put(Selection{
+		Key:  remote,
+		Path: path,
+		Time: now,
+	}); err != nil {
+		return err
+	}
+
+	// remember when this service account was last issued
+	return db.Bucket("last_used").Put(Selection{
+		Key:  path,
+		Path: path,
+		Time: now,
+	})
+}
+
+func GetSelected(remote string) string {
+	if err := Acquire(); err != nil {
+		log.WithError(err).Errorf("Failed checking selected bucket for: %q", remote)
+		return ""
+	}
+	defer Release()
+
+	var item Selection
+	if err := db.Bucket("selected").Get(remote, &item); err != nil {
+		if err != bow.ErrNotFound {
+			log.WithError(err).Errorf("Failed checking selected bucket for: %q", remote)
+		}
+		return ""
+	}
+
+	return item.Path
+}
+
+func GetLastUsed(path string) time.Time {
+	if err := Acquire(); err != nil {
+		log.WithError(err).Errorf("Failed checking last_used bucket for: %q", path)
+		return time.Time{}
+	}
+	defer Release()
+
+	var item Selection
+	if err := db.Bucket("last_used").Get(path, &item); err != nil {
+		if err != bow.ErrNotFound {
+			log.WithError(err).Errorf("Failed checking last_used bucket for: %q", path)
+		}
+		return time.Time{}
+	}
+
+	return item.Time
+}
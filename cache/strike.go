@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"github.com/zippoxer/bow"
+	"time"
+)
+
+type Strike struct {
+	Key   string `bow:"key"`
+	Count int
+	Last  time.Time
+}
+
+func AddStrike(key string, resetAfter time.Duration) (int, error) {
+	if err := Acquire(); err != nil {
+		return 0, err
+	}
+	defer Release()
+
+	// retrieve existing strikes
+	var item Strike
+	err := db.Bucket("strikes").Get(key, &item)
+	if err != nil && err != bow.ErrNotFound {
+		return 0, err
+	}
+
+	// strikes are forgotten when the key has behaved for long enough
+	now := time.Now().UTC()
+	if err == bow.ErrNotFound || now.Sub(item.Last) > resetAfter {
+		item = Strike{Key: key}
+	}
+
+	item.Count++
+	item.Last = now
+
+	if err := db.Bucket("strikes").Put(item); err != nil {
+		return 0, err
+	}
+
+	return item.Count, nil
+}
+
+func ClearStrikes(key string) error {
+	if err := Acquire(); err != nil {
+		return err
+	}
+	defer Release()
+
+	if err := db.Bucket("strikes").Delete(key); err != nil && err != bow.ErrNotFound {
+		return err
+	}
+
+	return nil
+}
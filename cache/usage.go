@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/zippoxer/bow"
+	"time"
+)
+
+type Usage struct {
+	Key   string `bow:"key"`
+	Path  string
+	Day   string
+	Bytes int64
+}
+
+const (
+	usageRetention = 7 * 24 * time.Hour
+)
+
+func AddUsage(key string, day string, bytes int64) error {
+	if err := Acquire(); err != nil {
+		return err
+	}
+	defer Release()
+
+	// retrieve existing usage
+	item := Usage{
+		Key:  usageKey(key, day),
+		Path: key,
+		Day:  day,
+	}
+
+	err := db.Bucket("usage").Get(item.Key, &item)
+	if err != nil && err != bow.ErrNotFound {
+		return err
+	}
+
+	item.Bytes += bytes
+
+	return db.Bucket("usage").Put(item)
+}
+
+func GetUsage(key string, day string) int64 {
+	if err := Acquire(); err != nil {
+		log.WithError(err).Errorf("Failed checking usage bucket for: %q", key)
+		return 0
+	}
+	defer Release()
+
+	var item Usage
+	err := db.Bucket("usage").Get(usageKey(key, day), &item)
+	if err == bow.ErrNotFound {
+		return 0
+	} else if err != nil {
+		log.WithError(err).Errorf("Failed checking usage bucket for: %q", key)
+		return 0
+	}
+
+	return item.Bytes
+}
+
+func ClearExpiredUsage() {
+	if err := Acquire(); err != nil {
+		log.WithError(err).Error("Failed clearing expired usage")
+		return
+	}
+	defer Release()
+
+	cutoff := time.Now().UTC().Add(-usageRetention).Format("2006-01-02")
+
+	iter := db.Bucket("usage").Iter()
+	defer iter.Close()
+
+	expired := make([]string, 0)
+
+	var page Usage
+	for iter.Next(&page) {
+		if page.Day < cutoff {
+			expired = append(expired, page.Key)
+		}
+	}
+
+	for _, key := range expired {
+		if err := db.Bucket("usage").Delete(key); err != nil {
+			log.WithError(err).Errorf("Failed removing from usage bucket: %q", key)
+		}
+	}
+}
+
+func usageKey(key string, day string) string {
+	return fmt.Sprintf("%s|%s", day, key)
+}
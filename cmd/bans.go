@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/reutils"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type banEntry struct {
+	Key       string    `json:"key"`
+	Expires   time.Time `json:"expires"`
+	ExpiresIn string    `json:"expires_in"`
+}
+
+var (
+	flagBansJSON bool
+	flagBansAll  bool
+)
+
+var bansCmd = &cobra.Command{
+	Use:   "bans",
+	Short: "Manage banned remote(s) & service account(s)",
+	Long:  `This command can be used to list, set & clear bans stored in the cache.`,
+}
+
+var bansListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List banned remote(s) & service account(s)",
+	Long:  `This command can be used to list the remote(s) & service account(s) that are currently banned.`,
+	Args:  cobra.NoArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore(false)
+		defer cache.Close()
+
+		// retrieve bans
+		bans, err := cache.GetBans()
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving bans")
+		}
+
+		entries := make([]banEntry, 0, len(bans))
+		for _, b := range bans {
+			entries = append(entries, banEntry{
+				Key:       b.Path,
+				Expires:   b.Expires,
+				ExpiresIn: humanize.Time(b.Expires),
+			})
+		}
+
+		// json output
+		if flagBansJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+
+			if err := enc.Encode(entries); err != nil {
+				log.WithError(err).Fatal("Failed encoding bans")
+			}
+			return
+		}
+
+		// table output
+		if len(entries) == 0 {
+			fmt.Println("There are no bans")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "KEY\tEXPIRES\tEXPIRES IN")
+		for _, e := range entries {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", e.Key, e.Expires.Local().Format(time.RFC3339), e.ExpiresIn)
+		}
+		_ = w.Flush()
+	},
+}
+
+var bansClearCmd = &cobra.Command{
+	Use:   "clear [key|glob]",
+	Short: "Clear banned remote(s) & service account(s)",
+	Long:  `This command can be used to clear a ban, all bans matching a glob (e.g. "*.json") or all bans with --all.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+
+		if len(args) == 0 && !flagBansAll {
+			return errors.New("you must specify a key / glob to clear, or --all")
+		}
+
+		return nil
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore(false)
+		defer cache.Close()
+
+		// retrieve bans
+		bans, err := cache.GetBans()
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving bans")
+		}
+
+		// determine bans to clear
+		match := func(key string) bool { return true }
+
+		if !flagBansAll {
+			pattern := strings.TrimSuffix(args[0], ":")
+
+			if strings.ContainsAny(pattern, "*?[{") {
+				re, err := reutils.GlobToRegexp(pattern, false)
+				if err != nil {
+					log.WithError(err).Fatalf("Failed parsing glob: %q", pattern)
+				}
+
+				match = func(key string) bool {
+					return re.MatchString(strings.TrimPrefix(key, "/"))
+				}
+			} else {
+				match = func(key string) bool {
+					return key == pattern
+				}
+			}
+		}
+
+		// clear bans
+		cleared := 0
+		for _, b := range bans {
+			if !match(b.Path) {
+				continue
+			}
+
+			if _, err := cache.ClearBan(b.Path); err != nil {
+				log.WithError(err).Errorf("Failed clearing ban: %q", b.Path)
+				continue
+			}
+
+			log.Infof("Cleared ban: %q", b.Path)
+			cleared++
+		}
+
+		log.Infof("Cleared %d ban(s)", cleared)
+	},
+}
+
+var bansSetCmd = &cobra.Command{
+	Use:   "set <key> <duration>",
+	Short: "Ban a remote or service account",
+	Long:  `This command can be used to ban a remote (e.g. before maintenance) or service account for a duration (e.g. 12h).`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+			return err
+		}
+
+		if d, err := time.ParseDuration(args[1]); err != nil || d <= 0 {
+			return fmt.Errorf("invalid ban duration: %q", args[1])
+		}
+
+		return nil
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		key := strings.TrimSuffix(args[0], ":")
+		duration, _ := time.ParseDuration(args[1])
+
+		// init core
+		initCore(false)
+		defer cache.Close()
+
+		// set ban
+		expires := time.Now().UTC().Add(duration)
+		if err := cache.SetBanned(key, expires); err != nil {
+			log.WithError(err).Fatalf("Failed banning: %q", key)
+		}
+
+		log.Infof("Banned %q until %v (%s)", key, expires.Local().Format(time.RFC3339), humanize.Time(expires))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bansCmd)
+	bansCmd.AddCommand(bansListCmd, bansClearCmd, bansSetCmd)
+
+	bansListCmd.Flags().BoolVar(&flagBansJSON, "json", false, "Output as JSON")
+	bansClearCmd.Flags().BoolVar(&flagBansAll, "all", false, "Clear all bans")
+}
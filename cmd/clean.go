@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/config"
 	"github.com/l3uddz/crop/uploader"
 	"github.com/pkg/errors"
@@ -19,12 +21,22 @@ var cleanCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		// init core
 		initCore(true)
-		defer releaseFileLock()
+		defer cache.Close()
+
+		ctx := cmd.Context()
 
 		// iterate uploader's
 		started := time.Now().UTC()
 
-		for _, uploaderConfig := range config.Config.Uploader {
+		for _, uploaderConfig := range config.Get().Uploader {
+			uploaderConfig := uploaderConfig
+
+			// stop when interrupted
+			if ctx.Err() != nil {
+				log.Warn("Interrupted, skipping remaining uploader(s)")
+				break
+			}
+
 			log := log.WithField("uploader", uploaderConfig.Name)
 
 			// skip disabled uploader(s)
@@ -39,17 +51,31 @@ var cleanCmd = &cobra.Command{
 				continue
 			}
 
+			// acquire uploader lock
+			l, err := acquireJobLock(ctx, lockScopeUploader, uploaderConfig.Name)
+			if err != nil {
+				log.WithError(err).Error("Failed acquiring uploader lock, skipping...")
+				continue
+			}
+
 			// create uploader
-			upload, err := uploader.New(config.Config, &uploaderConfig, uploaderConfig.Name)
+			upload, err := uploader.New(config.Get(), &uploaderConfig, uploaderConfig.Name)
 			if err != nil {
 				log.WithError(err).Error("Failed initializing uploader, skipping...")
+				releaseJobLock(l)
 				continue
 			}
 
 			log.Info("Clean commencing...")
 
-			// perform upload
-			if err := performClean(upload); err != nil {
+			// perform clean
+			cleanStarted := time.Now()
+			err = performClean(ctx, upload)
+			recordRun("clean", lockScopeUploader, upload.Name, cleanStarted,
+				len(upload.HiddenFiles)+len(upload.HiddenFolders), upload.Stats, err)
+			releaseJobLock(l)
+
+			if err != nil {
 				upload.Log.WithError(err).Error("Error occurred while running clean, skipping...")
 				continue
 			}
@@ -65,7 +91,7 @@ func init() {
 	cleanCmd.Flags().StringVarP(&flagUploader, "uploader", "u", "", "Run for a specific uploader")
 }
 
-func performClean(u *uploader.Uploader) error {
+func performClean(ctx context.Context, u *uploader.Uploader) error {
 	u.Log.Info("Running cleans...")
 
 	/* Cleans */
@@ -82,7 +108,7 @@ func performClean(u *uploader.Uploader) error {
 			EnableWaitForAll(true)
 
 		// queue clean tasks
-		err := u.PerformCleans(gp)
+		err := u.PerformCleans(ctx, gp)
 		if err != nil {
 			return errors.Wrap(err, "failed clearing remotes")
 		}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/notify"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/l3uddz/crop/uploader"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration related commands",
+	Long:  `This command can be used to work with the configuration file.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration file",
+	Long:  `This command can be used to check the configuration file for unknown keys, invalid values & broken references.`,
+	Args:  cobra.NoArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init paths & logging (the config is loaded by the validator)
+		initPaths()
+		initLogging()
+
+		// validate config
+		errs, err := config.Validate(flagConfigFile, config.ValidateOptions{
+			CheckTypes:      uploader.SupportedCheckTypes(),
+			CheckOperators:  uploader.SupportedCheckOperators(),
+			CheckDirections: uploader.SupportedCheckDirections(),
+			CleanerTypes:    uploader.SupportedCleanerTypes(),
+			Strategies:      rclone.SupportedStrategies(),
+			Backends:        rclone.SupportedBackends(),
+
+			NotificationTypes:  notify.SupportedTypes(),
+			NotificationEvents: notify.SupportedEvents(),
+		})
+		if err != nil {
+			log.WithError(err).Fatal("Failed validating config")
+		}
+
+		if len(errs) == 0 {
+			fmt.Printf("%s is valid\n", flagConfigFile)
+			return
+		}
+
+		for _, e := range errs {
+			file := e.File
+			if file == "" {
+				file = flagConfigFile
+			}
+
+			fmt.Printf("%s: %v\n", file, e)
+		}
+
+		fmt.Printf("\nFound %d error(s)\n", len(errs))
+		os.Exit(1)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
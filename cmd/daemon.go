@@ -0,0 +1,520 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/l3uddz/crop/api"
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/notify"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/l3uddz/crop/uploader"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+type daemon struct {
+	cron *cron.Cron
+
+	// cancelled on shutdown, stopping running task(s)
+	ctx context.Context
+
+	// guards jobs & cfg, which are swapped on reload and read by the api & starting task(s)
+	jobsMtx sync.Mutex
+	jobs    map[string]*daemonJob
+	cfg     *config.Configuration
+
+	// run(s) triggered via the api, waited for on shutdown
+	runs sync.WaitGroup
+}
+
+type daemonJob struct {
+	d        *daemon
+	log      *logrus.Entry
+	scope    string
+	name     string
+	schedule string
+	config   interface{}
+	entry    cron.EntryID
+	running  int32
+	fn       func(ctx context.Context, cfg *config.Configuration)
+
+	// cancels the current run
+	cancelMtx sync.Mutex
+	cancel    context.CancelFunc
+}
+
+type cronLogger struct {
+	log *logrus.Entry
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run uploader & syncer task(s) on a schedule",
+	Long:  `This command can be used to keep crop running, triggering uploader & syncer task(s) on their schedule.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore(true)
+		defer cache.Close()
+
+		// create scheduler
+		cl := cronLogger{log: log}
+		d := &daemon{
+			cron: cron.New(cron.WithLogger(cl), cron.WithChain(cron.Recover(cl))),
+			ctx:  cmd.Context(),
+			jobs: make(map[string]*daemonJob),
+		}
+
+		// schedule task(s)
+		count := d.schedule(config.Get())
+		if count == 0 {
+			log.Fatal("There were no uploader(s) or syncer(s) with a schedule, nothing to do...")
+		}
+
+		// serve metrics
+		serveMetrics(d.ctx)
+
+		// serve api
+		switch listen := config.Get().API.Listen; {
+		case listen == "":
+			break
+		case config.Get().API.Token == "":
+			log.Error("Not starting api server as api.token is not set")
+		default:
+			srv := api.New(listen, config.Get().API.Token, d)
+			srv.Run()
+			defer srv.Stop()
+		}
+
+		// start scheduler
+		d.cron.Start()
+		log.Infof("Daemon started with %d scheduled task(s)", count)
+
+		// reload config on change
+		stopWatch := make(chan struct{})
+		defer close(stopWatch)
+
+		reloadChan := make(chan struct{}, 1)
+		requestReload := func() {
+			select {
+			case reloadChan <- struct{}{}:
+			default:
+			}
+		}
+
+		if err := config.Watch(flagConfigFile, stopWatch, requestReload); err != nil {
+			log.WithError(err).Error("Failed watching config for changes, send SIGHUP to reload")
+		}
+
+		// reload config on SIGHUP
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGHUP)
+		defer signal.Stop(sigChan)
+
+		// wait for shutdown
+		for {
+			select {
+			case <-reloadChan:
+				d.reload()
+				continue
+			case <-sigChan:
+				requestReload()
+				continue
+			case <-d.ctx.Done():
+				log.Info("Shutting down, stopping running task(s)...")
+			}
+
+			break
+		}
+
+		<-d.cron.Stop().Done()
+		d.waitRuns()
+		log.Info("Finished!")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func (d *daemon) reload() {
+	log.Info("Reloading config...")
+
+	// validate config
+	errs, err := config.Validate(flagConfigFile, config.ValidateOptions{
+		CheckTypes:      uploader.SupportedCheckTypes(),
+		CheckOperators:  uploader.SupportedCheckOperators(),
+		CheckDirections: uploader.SupportedCheckDirections(),
+		CleanerTypes:    uploader.SupportedCleanerTypes(),
+		Strategies:      rclone.SupportedStrategies(),
+		Backends:        rclone.SupportedBackends(),
+
+		NotificationTypes:  notify.SupportedTypes(),
+		NotificationEvents: notify.SupportedEvents(),
+	})
+	if err == nil && len(errs) > 0 {
+		err = fmt.Errorf("found %d error(s)", len(errs))
+	}
+
+	if err != nil {
+		for _, e := range errs {
+			log.Errorf("%s: %v", e.File, e)
+		}
+
+		log.WithError(err).Error("Invalid config, keeping the current config")
+		return
+	}
+
+	// load config
+	cfg, err := config.Load(flagConfigFile)
+	if err != nil {
+		log.WithError(err).Error("Failed loading config, keeping the current config")
+		return
+	}
+
+	setConfigOverrides(cfg)
+
+	// running task(s) keep the config they started with
+	if err := rclone.Init(cfg); err != nil {
+		log.WithError(err).Error("Failed initializing rclone with the new config, keeping the current config")
+		return
+	}
+
+	if err := notify.Init(cfg.Notifications); err != nil {
+		log.WithError(err).Error("Failed initializing notifications with the new config")
+	}
+
+	config.Set(cfg)
+
+	count := d.schedule(cfg)
+	log.Infof("Reloaded config with %d scheduled task(s)", count)
+}
+
+func (d *daemon) schedule(cfg *config.Configuration) int {
+	d.jobsMtx.Lock()
+	defer d.jobsMtx.Unlock()
+
+	d.cfg = cfg
+	wanted := make(map[string]*daemonJob)
+
+	// uploader's
+	for _, uploaderConfig := range cfg.Uploader {
+		uploaderConfig := uploaderConfig
+
+		log := log.WithField("uploader", uploaderConfig.Name)
+
+		// skip disabled uploader(s)
+		if !uploaderConfig.Enabled {
+			log.Debug("Skipping disabled uploader")
+			continue
+		}
+
+		// skip unscheduled uploader(s)
+		if uploaderConfig.Schedule == "" {
+			log.Debug("Skipping uploader without a schedule")
+			continue
+		}
+
+		j := &daemonJob{
+			d:        d,
+			log:      log,
+			scope:    lockScopeUploader,
+			name:     uploaderConfig.Name,
+			schedule: uploaderConfig.Schedule,
+			config:   uploaderConfig,
+			fn: func(ctx context.Context, cfg *config.Configuration) {
+				processUploader(ctx, cfg, &uploaderConfig)
+			},
+		}
+		wanted[j.key()] = j
+	}
+
+	// syncer's
+	parallelism := 0
+	for _, syncerConfig := range cfg.Syncer {
+		if syncerConfig.Enabled && syncerConfig.Schedule != "" {
+			parallelism++
+		}
+	}
+
+	for _, syncerConfig := range cfg.Syncer {
+		syncerConfig := syncerConfig
+
+		log := log.WithField("syncer", syncerConfig.Name)
+
+		// skip disabled syncer(s)
+		if !syncerConfig.Enabled {
+			log.Debug("Skipping disabled syncer")
+			continue
+		}
+
+		// skip unscheduled syncer(s)
+		if syncerConfig.Schedule == "" {
+			log.Debug("Skipping syncer without a schedule")
+			continue
+		}
+
+		j := &daemonJob{
+			d:        d,
+			log:      log,
+			scope:    lockScopeSyncer,
+			name:     syncerConfig.Name,
+			schedule: syncerConfig.Schedule,
+			config:   syncerConfig,
+			fn: func(ctx context.Context, cfg *config.Configuration) {
+				// create syncer
+				syncr := prepareSyncer(cfg, &syncerConfig, parallelism)
+				if syncr == nil {
+					return
+				}
+
+				// perform syncer job
+				if err := performSync(ctx, syncr); err != nil {
+					syncr.Log.WithError(err).Error("Error occurred while running syncer, skipping...")
+				}
+			},
+		}
+		wanted[j.key()] = j
+	}
+
+	// remove task(s) no longer wanted
+	for _, key := range sortedJobKeys(d.jobs) {
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+
+		j := d.jobs[key]
+		d.cron.Remove(j.entry)
+		delete(d.jobs, key)
+
+		j.log.Infof("Removed %s", j.scope)
+	}
+
+	// add / update task(s)
+	for _, key := range sortedJobKeys(wanted) {
+		w := wanted[key]
+
+		j, ok := d.jobs[key]
+		if ok {
+			// keep the existing job so that an in-progress run is still tracked
+			changed := !reflect.DeepEqual(j.config, w.config)
+
+			j.config = w.config
+			j.fn = w.fn
+
+			if !changed {
+				continue
+			}
+
+			if j.schedule == w.schedule {
+				j.log.Infof("Changed %s", j.scope)
+				continue
+			}
+
+			d.cron.Remove(j.entry)
+			j.schedule = w.schedule
+		} else {
+			j = w
+		}
+
+		id, err := d.cron.AddJob(j.schedule, j)
+		if err != nil {
+			j.log.WithError(err).Errorf("Failed scheduling %s with schedule: %q", j.scope, j.schedule)
+			delete(d.jobs, key)
+			continue
+		}
+
+		j.entry = id
+		d.jobs[key] = j
+
+		if ok {
+			j.log.Infof("Changed %s, rescheduled: %q", j.scope, j.schedule)
+		} else {
+			j.log.Infof("Scheduled %s: %q", j.scope, j.schedule)
+		}
+	}
+
+	return len(d.jobs)
+}
+
+func (j *daemonJob) Run() {
+	// skip runs triggered while shutting down
+	if j.d.ctx.Err() != nil {
+		return
+	}
+
+	// skip if the previous run is still in progress
+	if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+		j.log.Warn("Skipping scheduled run as the previous run has not finished")
+		return
+	}
+	defer atomic.StoreInt32(&j.running, 0)
+
+	// skip if another crop instance is running this task
+	l, err := tryJobLock(j.scope, j.name)
+	if err != nil {
+		j.log.WithError(err).Warn("Skipping scheduled run as the lock could not be acquired")
+		return
+	}
+	defer releaseJobLock(l)
+
+	// allow this run to be cancelled on its own
+	ctx, cancel := context.WithCancel(j.d.ctx)
+	defer cancel()
+
+	j.setCancel(cancel)
+	defer j.setCancel(nil)
+
+	// use the config at the start of this run, a reload during it applies to the next run
+	j.d.jobsMtx.Lock()
+	fn, cfg := j.fn, j.d.cfg
+	j.d.jobsMtx.Unlock()
+
+	fn(ctx, cfg)
+}
+
+func (j *daemonJob) setCancel(cancel context.CancelFunc) {
+	j.cancelMtx.Lock()
+	defer j.cancelMtx.Unlock()
+
+	j.cancel = cancel
+}
+
+func (d *daemon) Jobs() []api.Job {
+	d.jobsMtx.Lock()
+	defer d.jobsMtx.Unlock()
+
+	jobs := make([]api.Job, 0, len(d.jobs))
+	for _, key := range sortedJobKeys(d.jobs) {
+		j := d.jobs[key]
+
+		jobs = append(jobs, api.Job{
+			Scope:    j.scope,
+			Name:     j.name,
+			Schedule: j.schedule,
+			Running:  atomic.LoadInt32(&j.running) == 1,
+			NextRun:  d.cron.Entry(j.entry).Next,
+		})
+	}
+
+	return jobs
+}
+
+func (d *daemon) RunJob(scope string, name string) error {
+	j := d.find(scope, name)
+	if j == nil {
+		return api.ErrJobNotFound
+	}
+
+	if atomic.LoadInt32(&j.running) == 1 {
+		return api.ErrJobRunning
+	}
+
+	j.log.Info("Running now, as requested via the api")
+
+	d.jobsMtx.Lock()
+	defer d.jobsMtx.Unlock()
+
+	// runs are not tracked once shutting down, they would be skipped anyway
+	if d.ctx.Err() != nil {
+		return nil
+	}
+
+	d.runs.Add(1)
+	go func() {
+		defer d.runs.Done()
+		j.Run()
+	}()
+
+	return nil
+}
+
+func (d *daemon) waitRuns() {
+	// ensure no further api run(s) are tracked, RunJob checks the ctx under this lock
+	d.jobsMtx.Lock()
+	d.jobsMtx.Unlock()
+
+	d.runs.Wait()
+}
+
+func (d *daemon) CancelJob(scope string, name string) error {
+	j := d.find(scope, name)
+	if j == nil {
+		return api.ErrJobNotFound
+	}
+
+	j.cancelMtx.Lock()
+	defer j.cancelMtx.Unlock()
+
+	if j.cancel == nil {
+		return api.ErrJobNotRunning
+	}
+
+	j.log.Warn("Cancelling, as requested via the api")
+	j.cancel()
+
+	return nil
+}
+
+func (d *daemon) ServiceAccounts() ([]rclone.RemoteCapacity, error) {
+	d.jobsMtx.Lock()
+	cfg := d.cfg
+	d.jobsMtx.Unlock()
+
+	return serviceAccountCapacity(cfg)
+}
+
+func (d *daemon) find(scope string, name string) *daemonJob {
+	d.jobsMtx.Lock()
+	defer d.jobsMtx.Unlock()
+
+	for _, j := range d.jobs {
+		if strings.EqualFold(j.scope, scope) && strings.EqualFold(j.name, name) {
+			return j
+		}
+	}
+
+	return nil
+}
+
+func (j *daemonJob) key() string {
+	return fmt.Sprintf("%s/%s", j.scope, j.name)
+}
+
+func sortedJobKeys(jobs map[string]*daemonJob) []string {
+	keys := make([]string, 0, len(jobs))
+	for k := range jobs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (l cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log.WithFields(cronFields(keysAndValues)).Tracef("Scheduler: %s", msg)
+}
+
+func (l cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.log.WithError(err).WithFields(cronFields(keysAndValues)).Errorf("Scheduler: %s", msg)
+}
+
+func cronFields(keysAndValues []interface{}) logrus.Fields {
+	fields := make(logrus.Fields)
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+
+	return fields
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"github.com/l3uddz/crop/config"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestDaemonSchedule(t *testing.T) {
+	if log == nil {
+		l := logrus.New()
+		l.SetOutput(ioutil.Discard)
+		log = logrus.NewEntry(l)
+	}
+
+	d := &daemon{
+		cron: cron.New(),
+		ctx:  context.Background(),
+		jobs: make(map[string]*daemonJob),
+	}
+
+	uploader := func(name string, schedule string, folder string) config.UploaderConfig {
+		return config.UploaderConfig{Name: name, Enabled: true, Schedule: schedule, LocalFolder: folder}
+	}
+	syncer := func(name string, schedule string) config.SyncerConfig {
+		return config.SyncerConfig{Name: name, Enabled: true, Schedule: schedule}
+	}
+
+	steps := []struct {
+		name string
+		cfg  *config.Configuration
+		// keys of the scheduled jobs, with whether they kept their job & cron entry from the previous step
+		want map[string][2]bool
+	}{
+		{"disabled & unscheduled are skipped", &config.Configuration{
+			Uploader: []config.UploaderConfig{
+				uploader("tv", "@every 1h", "/mnt/tv"),
+				{Name: "movies", Schedule: "@every 1h"},
+			},
+			Syncer: []config.SyncerConfig{
+				syncer("backup", "@every 2h"),
+				syncer("manual", ""),
+			},
+		}, map[string][2]bool{
+			"uploader/tv":   {false, false},
+			"syncer/backup": {false, false},
+		}},
+		{"changed, rescheduled & added", &config.Configuration{
+			Uploader: []config.UploaderConfig{
+				uploader("tv", "@every 1h", "/mnt/shows"),
+				uploader("anime", "@every 1h", "/mnt/anime"),
+			},
+			Syncer: []config.SyncerConfig{
+				syncer("backup", "@every 3h"),
+			},
+		}, map[string][2]bool{
+			"uploader/tv":    {true, true},
+			"uploader/anime": {false, false},
+			"syncer/backup":  {true, false},
+		}},
+		{"removed & unchanged", &config.Configuration{
+			Uploader: []config.UploaderConfig{
+				uploader("anime", "@every 1h", "/mnt/anime"),
+			},
+			Syncer: []config.SyncerConfig{
+				syncer("backup", "@every 3h"),
+			},
+		}, map[string][2]bool{
+			"uploader/anime": {true, true},
+			"syncer/backup":  {true, true},
+		}},
+	}
+
+	for _, s := range steps {
+		previousJobs := make(map[string]*daemonJob)
+		previousEntries := make(map[string]cron.EntryID)
+		for key, j := range d.jobs {
+			previousJobs[key] = j
+			previousEntries[key] = j.entry
+		}
+
+		if got := d.schedule(s.cfg); got != len(s.want) {
+			t.Errorf("%s: schedule() = %d, want %d", s.name, got, len(s.want))
+		}
+
+		if got := len(d.cron.Entries()); got != len(s.want) {
+			t.Errorf("%s: %d cron entries, want %d", s.name, got, len(s.want))
+		}
+
+		for key, want := range s.want {
+			j, ok := d.jobs[key]
+			if !ok {
+				t.Errorf("%s: %q was not scheduled", s.name, key)
+				continue
+			}
+
+			if kept := previousJobs[key] == j; kept != want[0] {
+				t.Errorf("%s: %q kept its job = %v, want %v", s.name, key, kept, want[0])
+			}
+
+			entry, ok := previousEntries[key]
+			if sameEntry := ok && entry == j.entry; sameEntry != want[1] {
+				t.Errorf("%s: %q kept its cron entry = %v, want %v", s.name, key, sameEntry, want[1])
+			}
+
+			if e := d.cron.Entry(j.entry); !e.Valid() || e.Job != j {
+				t.Errorf("%s: %q cron entry does not run its job", s.name, key)
+			}
+
+			if !reflect.DeepEqual(j.config, configFor(s.cfg, j.scope, j.name)) {
+				t.Errorf("%s: %q config = %+v, want the new config", s.name, key, j.config)
+			}
+		}
+	}
+}
+
+func configFor(cfg *config.Configuration, scope string, name string) interface{} {
+	if scope == lockScopeUploader {
+		for _, u := range cfg.Uploader {
+			if u.Name == name {
+				return u
+			}
+		}
+	}
+
+	for _, s := range cfg.Syncer {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return nil
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/config"
 	"github.com/l3uddz/crop/uploader"
 	"github.com/pkg/errors"
@@ -18,12 +20,22 @@ var dedupeCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		// init core
 		initCore(true)
-		defer releaseFileLock()
+		defer cache.Close()
+
+		ctx := cmd.Context()
 
 		// iterate uploader's
 		started := time.Now().UTC()
 
-		for _, uploaderConfig := range config.Config.Uploader {
+		for _, uploaderConfig := range config.Get().Uploader {
+			uploaderConfig := uploaderConfig
+
+			// stop when interrupted
+			if ctx.Err() != nil {
+				log.Warn("Interrupted, skipping remaining uploader(s)")
+				break
+			}
+
 			log := log.WithField("uploader", uploaderConfig.Name)
 
 			// skip disabled uploader(s)
@@ -38,17 +50,30 @@ var dedupeCmd = &cobra.Command{
 				continue
 			}
 
+			// acquire uploader lock
+			l, err := acquireJobLock(ctx, lockScopeUploader, uploaderConfig.Name)
+			if err != nil {
+				log.WithError(err).Error("Failed acquiring uploader lock, skipping...")
+				continue
+			}
+
 			// create uploader
-			upload, err := uploader.New(config.Config, &uploaderConfig, uploaderConfig.Name)
+			upload, err := uploader.New(config.Get(), &uploaderConfig, uploaderConfig.Name)
 			if err != nil {
 				log.WithError(err).Error("Failed initializing uploader, skipping...")
+				releaseJobLock(l)
 				continue
 			}
 
 			log.Info("Dedupe commencing...")
 
-			// perform upload
-			if err := performDedupe(upload); err != nil {
+			// perform dedupe
+			dedupeStarted := time.Now()
+			err = performDedupe(ctx, upload)
+			recordRun("dedupe", lockScopeUploader, upload.Name, dedupeStarted, 0, upload.Stats, err)
+			releaseJobLock(l)
+
+			if err != nil {
 				upload.Log.WithError(err).Error("Error occurred while running dedupe, skipping...")
 				continue
 			}
@@ -64,11 +89,11 @@ func init() {
 	dedupeCmd.Flags().StringVarP(&flagUploader, "uploader", "u", "", "Run for a specific uploader")
 }
 
-func performDedupe(u *uploader.Uploader) error {
+func performDedupe(ctx context.Context, u *uploader.Uploader) error {
 	u.Log.Info("Running dedupe...")
 
 	/* Dedupe */
-	err := u.Dedupe(nil)
+	err := u.Dedupe(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "failed dedupe remotes")
 	}
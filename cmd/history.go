@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/metrics"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+type historyEntry struct {
+	Command         string    `json:"command"`
+	Scope           string    `json:"scope"`
+	Name            string    `json:"name"`
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished"`
+	Elapsed         string    `json:"elapsed"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	Files           int       `json:"files"`
+	Bytes           int64     `json:"bytes"`
+	Transfers       int       `json:"transfers"`
+	Checks          int       `json:"checks"`
+	Deletes         int       `json:"deletes"`
+	Renames         int       `json:"renames"`
+	Errors          int       `json:"errors"`
+	ServiceAccounts []string  `json:"service_accounts,omitempty"`
+}
+
+const (
+	defaultHistoryRetention = 90 * 24 * time.Hour
+)
+
+var (
+	flagHistoryJSON      bool
+	flagHistoryName      string
+	flagHistoryCommand   string
+	flagHistoryStatus    string
+	flagHistorySince     string
+	flagHistoryUntil     string
+	flagHistoryLimit     int
+	flagHistoryOlderThan time.Duration
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List previous upload, sync, clean & dedupe run(s)",
+	Long:  `This command can be used to list the run(s) recorded in the cache, most recent last.`,
+	Args:  cobra.NoArgs,
+
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := parseHistoryTime(flagHistorySince); err != nil {
+			return fmt.Errorf("invalid --since: %q", flagHistorySince)
+		}
+
+		if _, err := parseHistoryTime(flagHistoryUntil); err != nil {
+			return fmt.Errorf("invalid --until: %q", flagHistoryUntil)
+		}
+
+		return nil
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		since, _ := parseHistoryTime(flagHistorySince)
+		until, _ := parseHistoryTime(flagHistoryUntil)
+
+		// init core
+		initCore(false)
+		defer cache.Close()
+
+		// retrieve runs
+		runs, err := cache.GetRuns(cache.RunFilter{
+			Name:    flagHistoryName,
+			Command: flagHistoryCommand,
+			Status:  flagHistoryStatus,
+			Since:   since,
+			Until:   until,
+			Limit:   flagHistoryLimit,
+		})
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving history")
+		}
+
+		entries := make([]historyEntry, 0, len(runs))
+		for _, r := range runs {
+			entries = append(entries, historyEntry{
+				Command:         r.Command,
+				Scope:           r.Scope,
+				Name:            r.Name,
+				Started:         r.Started,
+				Finished:        r.Finished,
+				Elapsed:         r.Finished.Sub(r.Started).Round(time.Second).String(),
+				Status:          r.Status,
+				Error:           r.Error,
+				Files:           r.Files,
+				Bytes:           r.Bytes,
+				Transfers:       r.Transfers,
+				Checks:          r.Checks,
+				Deletes:         r.Deletes,
+				Renames:         r.Renames,
+				Errors:          r.Errors,
+				ServiceAccounts: r.ServiceAccounts,
+			})
+		}
+
+		// json output
+		if flagHistoryJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+
+			if err := enc.Encode(entries); err != nil {
+				log.WithError(err).Fatal("Failed encoding history")
+			}
+			return
+		}
+
+		// table output
+		if len(entries) == 0 {
+			fmt.Println("There are no runs")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "STARTED\tCOMMAND\tNAME\tSTATUS\tELAPSED\tFILES\tTRANSFERRED\tTRANSFERS\tDELETES\tERRORS\tSAS\tERROR")
+		for _, e := range entries {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%d\t%d\t%d\t%d\t%s\n",
+				e.Started.Local().Format(time.RFC3339), e.Command, e.Name, e.Status, e.Elapsed, e.Files,
+				humanize.IBytes(uint64(e.Bytes)), e.Transfers, e.Deletes, e.Errors, len(e.ServiceAccounts), e.Error)
+		}
+		_ = w.Flush()
+	},
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old run(s) from the history",
+	Long:  `This command can be used to remove run(s) that started longer ago than --older-than (e.g. 720h).`,
+	Args:  cobra.NoArgs,
+
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if flagHistoryOlderThan <= 0 {
+			return errors.New("you must specify --older-than, e.g. 720h")
+		}
+
+		return nil
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore(false)
+		defer cache.Close()
+
+		// prune runs
+		removed, err := cache.PruneRuns(time.Now().Add(-flagHistoryOlderThan))
+		if err != nil {
+			log.WithError(err).Fatal("Failed pruning history")
+		}
+
+		log.Infof("Removed %d run(s)", removed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+
+	historyCmd.Flags().BoolVar(&flagHistoryJSON, "json", false, "Output as JSON")
+	historyCmd.Flags().StringVarP(&flagHistoryName, "name", "n", "", "Only runs of this uploader / syncer")
+	historyCmd.Flags().StringVar(&flagHistoryCommand, "command", "", "Only runs of this command (upload, sync, clean or dedupe)")
+	historyCmd.Flags().StringVarP(&flagHistoryStatus, "status", "s", "",
+		fmt.Sprintf("Only runs with this status (%s, %s or %s)", metrics.StatusSuccess, metrics.StatusFailure,
+			metrics.StatusCancelled))
+	historyCmd.Flags().StringVar(&flagHistorySince, "since", "", "Only runs started since (e.g. 24h, 2021-01-31 or RFC3339)")
+	historyCmd.Flags().StringVar(&flagHistoryUntil, "until", "", "Only runs started until (e.g. 24h, 2021-01-31 or RFC3339)")
+	historyCmd.Flags().IntVar(&flagHistoryLimit, "limit", 0, "Only the most recent runs (0 = all)")
+
+	historyPruneCmd.Flags().DurationVar(&flagHistoryOlderThan, "older-than", 0, "Remove runs started longer ago than")
+}
+
+func recordRun(command string, scope string, name string, started time.Time, files int, stats rclone.Stats,
+	err error) {
+	r := &cache.Run{
+		Command:         command,
+		Scope:           scope,
+		Name:            name,
+		Started:         started.UTC(),
+		Finished:        time.Now().UTC(),
+		Status:          metrics.RunStatus(err),
+		Files:           files,
+		Bytes:           stats.Bytes,
+		Transfers:       stats.Transfers,
+		Checks:          stats.Checks,
+		Deletes:         stats.Deletes,
+		Renames:         stats.Renames,
+		Errors:          stats.Errors,
+		ServiceAccounts: stats.ServiceAccounts,
+	}
+
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	if err := cache.AddRun(r); err != nil {
+		log.WithError(err).Errorf("Failed recording %s run of: %q", command, name)
+		return
+	}
+
+	// remove runs past the retention
+	retention := historyRetention(config.Get())
+	if retention == 0 {
+		return
+	}
+
+	removed, err := cache.PruneRuns(time.Now().Add(-retention))
+	if err != nil {
+		log.WithError(err).Error("Failed pruning history")
+		return
+	}
+
+	if removed > 0 {
+		log.Debugf("Removed %d run(s) older than %v from the history", removed, retention)
+	}
+}
+
+func historyRetention(cfg *config.Configuration) time.Duration {
+	if cfg == nil || cfg.History.Retention == "" {
+		return defaultHistoryRetention
+	}
+
+	d, err := time.ParseDuration(cfg.History.Retention)
+	if err != nil || d < 0 {
+		log.WithError(err).Warnf("Invalid history.retention %q, using %v", cfg.History.Retention,
+			defaultHistoryRetention)
+		return defaultHistoryRetention
+	}
+
+	return d
+}
+
+func parseHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	// relative to now, e.g. 24h
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -30,7 +30,6 @@ var manualCmd = &cobra.Command{
 		// init core
 		initCore(true)
 		defer cache.Close()
-		defer releaseFileLock()
 
 		// determine destination remotes
 		syncRemotes := make([]string, 0)
@@ -48,7 +47,7 @@ var manualCmd = &cobra.Command{
 		}
 
 		// create remote to service account map
-		remoteSaFolders := make(map[string][]string)
+		remoteSaFolders := make(map[string][]config.ServiceAccountRemote)
 
 		switch flagSaFolder != "" {
 		case true:
@@ -56,14 +55,15 @@ var manualCmd = &cobra.Command{
 				// source is a remote
 				srcRemote := stringutils.FromLeftUntil(flagSrc, ":")
 				log.Debugf("Using service account folder for %q: %v", srcRemote, flagSaFolder)
-				remoteSaFolders[flagSaFolder] = []string{srcRemote}
+				remoteSaFolders[flagSaFolder] = []config.ServiceAccountRemote{{Remote: srcRemote}}
 			}
 
 			if strings.Contains(flagDest, ":") {
 				// dest is a remote
 				dstRemote := stringutils.FromLeftUntil(flagDest, ":")
 				log.Debugf("Using service account folder for %q: %v", dstRemote, flagSaFolder)
-				remoteSaFolders[flagSaFolder] = append(remoteSaFolders[flagSaFolder], dstRemote)
+				remoteSaFolders[flagSaFolder] = append(remoteSaFolders[flagSaFolder],
+					config.ServiceAccountRemote{Remote: dstRemote})
 			}
 
 		default:
@@ -98,10 +98,10 @@ var manualCmd = &cobra.Command{
 		// create a config structure for manual sync
 		cfg := config.Configuration{
 			Rclone: config.RcloneConfig{
-				Path:                  config.Config.Rclone.Path,
-				Config:                config.Config.Rclone.Config,
-				Stats:                 config.Config.Rclone.Stats,
-				DryRun:                config.Config.Rclone.DryRun,
+				Path:                  config.Get().Rclone.Path,
+				Config:                config.Get().Rclone.Config,
+				Stats:                 config.Get().Rclone.Stats,
+				DryRun:                config.Get().Rclone.DryRun,
 				ServiceAccountRemotes: remoteSaFolders,
 			},
 			Uploader: nil,
@@ -146,7 +146,7 @@ var manualCmd = &cobra.Command{
 		log.Info("Syncer commencing...")
 
 		// perform sync
-		if err := performSync(sync); err != nil {
+		if err := performSync(cmd.Context(), sync); err != nil {
 			sync.Log.WithError(err).Fatal("Error occurred while running syncer, skipping...")
 		}
 
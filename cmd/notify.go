@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/notify"
+	"github.com/l3uddz/crop/rclone"
+	"time"
+)
+
+func notifyBanned(scope string, name string, message string, expiry time.Time) {
+	notify.Send(&notify.Notification{
+		Event:   notify.EventRemoteBanned,
+		Scope:   scope,
+		Name:    name,
+		Message: message,
+		Fields: map[string]interface{}{
+			"expires_time": expiry.UTC().Format(time.RFC3339),
+			"expires_in":   humanize.Time(expiry),
+		},
+	})
+}
+
+func notifyRunStarted(scope string, name string) {
+	notify.Send(&notify.Notification{
+		Event:   notify.EventRunStarted,
+		Scope:   scope,
+		Name:    name,
+		Message: "Running...",
+	})
+}
+
+func notifyRunResult(scope string, name string, stats rclone.Stats, elapsed time.Duration, err error) {
+	n := &notify.Notification{
+		Event:   notify.EventRunFinished,
+		Scope:   scope,
+		Name:    name,
+		Message: "Finished!",
+		Fields: map[string]interface{}{
+			"transferred": humanize.IBytes(uint64(stats.Bytes)),
+			"transfers":   stats.Transfers,
+			"deletes":     stats.Deletes,
+			"errors":      stats.Errors,
+			"elapsed":     elapsed.Round(time.Second).String(),
+		},
+	}
+
+	if err != nil {
+		n.Event = notify.EventRunFailed
+		n.Message = err.Error()
+	}
+
+	notify.Send(n)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	flagQuotaJSON bool
+)
+
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Show remaining service account capacity for each remote",
+	Long:  `This command can be used to show how much can still be uploaded today with the service accounts of each remote.`,
+	Args:  cobra.NoArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		initCore(false)
+		defer cache.Close()
+
+		capacity, err := serviceAccountCapacity(config.Get())
+		if err != nil {
+			log.WithError(err).Fatal("Failed determining service account capacity")
+		}
+
+		// json output
+		if flagQuotaJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+
+			if err := enc.Encode(capacity); err != nil {
+				log.WithError(err).Fatal("Failed encoding capacity")
+			}
+			return
+		}
+
+		// table output
+		if len(capacity) == 0 {
+			fmt.Println("There are no remotes with service accounts")
+			return
+		}
+
+		fmt.Printf("Quota day %s resets %s (daily quota: %s per service account)\n\n",
+			rclone.QuotaDay(time.Now()), humanize.Time(rclone.NextQuotaReset(time.Now())),
+			humanize.IBytes(rclone.DailyQuota()))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "REMOTE\tACCOUNTS\tAVAILABLE\tBANNED\tEXHAUSTED\tUSED\tREMAINING")
+		for _, c := range capacity {
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", c.Remote, c.ServiceAccounts, c.Available,
+				c.Banned, c.Exhausted, humanize.IBytes(c.Used), humanize.IBytes(c.Remaining))
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quotaCmd)
+
+	quotaCmd.Flags().BoolVar(&flagQuotaJSON, "json", false, "Output as JSON")
+}
+
+func serviceAccountCapacity(cfg *config.Configuration) ([]rclone.RemoteCapacity, error) {
+	// determine remotes using service accounts
+	remotes := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, folderRemotes := range cfg.Rclone.ServiceAccountRemotes {
+		for _, remote := range folderRemotes {
+			if seen[remote.Remote] {
+				continue
+			}
+
+			seen[remote.Remote] = true
+			remotes = append(remotes, remote.Remote+":")
+		}
+	}
+
+	// list service accounts, invalid files are left for the uploader(s) & syncer(s) to quarantine
+	sa := rclone.NewServiceAccountManager(cfg.Rclone.ServiceAccountRemotes, 1)
+	if err := sa.ListServiceAccounts(remotes); err != nil {
+		return nil, errors.WithMessage(err, "failed loading service accounts")
+	}
+
+	return sa.Capacity()
+}
@@ -1,19 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/lock"
 	"github.com/l3uddz/crop/logger"
+	"github.com/l3uddz/crop/metrics"
+	"github.com/l3uddz/crop/notify"
 	"github.com/l3uddz/crop/pathutils"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/l3uddz/crop/runtime"
 	"github.com/l3uddz/crop/stringutils"
-	"github.com/nightlyone/lockfile"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -25,6 +32,7 @@ var (
 	flagCachePath    = "cache"
 	flagLogFile      = "activity.log"
 	flagLockFile     = "crop.lock"
+	flagLockTimeout  time.Duration
 	flagDryRun       bool
 	flagNoDedupe     bool
 
@@ -32,8 +40,15 @@ var (
 	flagUploader string
 
 	// Global vars
-	log   *logrus.Entry
-	flock lockfile.Lockfile
+	log *logrus.Entry
+
+	// Internal
+	lockNameReplacer = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+const (
+	lockScopeUploader = "uploader"
+	lockScopeSyncer   = "syncer"
 )
 
 var rootCmd = &cobra.Command{
@@ -44,7 +59,20 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// cancel running task(s) on interrupt, a second interrupt exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		stop()
+
+		if log != nil {
+			log.Warn("Shutting down, interrupt again to exit immediately...")
+		}
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -56,7 +84,8 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&flagConfigFile, "config", "c", flagConfigFile, "Config file")
 	rootCmd.PersistentFlags().StringVarP(&flagCachePath, "cache", "d", flagCachePath, "Cache path")
 	rootCmd.PersistentFlags().StringVarP(&flagLogFile, "log", "l", flagLogFile, "Log file")
-	rootCmd.PersistentFlags().StringVarP(&flagLockFile, "lock", "f", flagLockFile, "Lock file")
+	rootCmd.PersistentFlags().StringVarP(&flagLockFile, "lock", "f", flagLockFile, "Lock file (suffixed per uploader/syncer)")
+	rootCmd.PersistentFlags().DurationVar(&flagLockTimeout, "lock-timeout", 0, "Give up waiting for a lock after (0 = never)")
 	rootCmd.PersistentFlags().CountVarP(&flagLogLevel, "verbose", "v", "Verbose level")
 
 	rootCmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Dry run mode")
@@ -64,37 +93,17 @@ func init() {
 
 func initCore(showAppInfo bool) {
 	// Set core variables
-	if !rootCmd.PersistentFlags().Changed("config") {
-		flagConfigFile = filepath.Join(flagConfigFolder, flagConfigFile)
-	}
-	if !rootCmd.PersistentFlags().Changed("cache") {
-		flagCachePath = filepath.Join(flagConfigFolder, flagCachePath)
-	}
-	if !rootCmd.PersistentFlags().Changed("log") {
-		flagLogFile = filepath.Join(flagConfigFolder, flagLogFile)
-	}
-	if !rootCmd.PersistentFlags().Changed("lock") {
-		flagLockFile = filepath.Join(flagConfigFolder, flagLockFile)
-	}
+	initPaths()
 
 	// Init Logging
-	if err := logger.Init(flagLogLevel, flagLogFile); err != nil {
-		log.WithError(err).Fatal("Failed to initialize logging")
-	}
-
-	log = logger.GetLogger("crop")
-
-	// Init File Lock
-	if err := acquireFileLock(); err != nil {
-		log.WithError(err).Fatalf("Failed acquiring file lock for %q", flagLockFile)
-	}
+	initLogging()
 
 	// Init Config
 	if err := config.Init(flagConfigFile); err != nil {
 		log.WithError(err).Fatal("Failed to initialize config")
 	}
 
-	setConfigOverrides()
+	setConfigOverrides(config.Get())
 
 	// Init Cache
 	if err := cache.Init(flagCachePath, flagLogLevel); err != nil {
@@ -102,55 +111,93 @@ func initCore(showAppInfo bool) {
 	}
 
 	// Init Rclone
-	if err := rclone.Init(config.Config); err != nil {
+	if err := rclone.Init(config.Get()); err != nil {
 		log.WithError(err).Fatal("Failed to initialize rclone")
 	}
 
+	// Init Notifications
+	if err := notify.Init(config.Get().Notifications); err != nil {
+		log.WithError(err).Fatal("Failed to initialize notifications")
+	}
+
 	// Show App Info
 	if showAppInfo {
 		showUsing()
 	}
 }
 
-func setConfigOverrides() {
+func initPaths() {
+	if !rootCmd.PersistentFlags().Changed("config") {
+		flagConfigFile = filepath.Join(flagConfigFolder, flagConfigFile)
+	}
+	if !rootCmd.PersistentFlags().Changed("cache") {
+		flagCachePath = filepath.Join(flagConfigFolder, flagCachePath)
+	}
+	if !rootCmd.PersistentFlags().Changed("log") {
+		flagLogFile = filepath.Join(flagConfigFolder, flagLogFile)
+	}
+	if !rootCmd.PersistentFlags().Changed("lock") {
+		flagLockFile = filepath.Join(flagConfigFolder, flagLockFile)
+	}
+}
+
+func initLogging() {
+	if err := logger.Init(flagLogLevel, flagLogFile); err != nil {
+		log.WithError(err).Fatal("Failed to initialize logging")
+	}
+
+	log = logger.GetLogger("crop")
+}
+
+func setConfigOverrides(cfg *config.Configuration) {
 	// set dry-run if enabled by flag
 	if flagDryRun {
-		config.Config.Rclone.DryRun = true
+		cfg.Rclone.DryRun = true
 	}
 }
 
-func acquireFileLock() error {
-	f, err := lockfile.New(flagLockFile)
+func acquireJobLock(ctx context.Context, scope string, name string) (*lock.Lock, error) {
+	l, err := lock.New(jobLockPath(scope, name))
 	if err != nil {
-		return err
-	}
-
-	flock = f
-
-	// loop until lock has been acquired
-	for {
-		err = flock.TryLock()
-		switch {
-		case err == nil:
-			// lock has been acquired
-			return nil
-		case err == lockfile.ErrBusy:
-			// another instance is already running
-			log.Warnf("There is another crop instance running, re-checking in 1 minute...")
-			time.Sleep(1 * time.Minute)
-		default:
-			// an un-expected error, propagate down-stream
-			return err
-		}
+		return nil, err
+	}
+
+	// wait until lock has been acquired (or timeout)
+	if err := l.Lock(ctx, flagLockTimeout); err != nil {
+		return nil, err
 	}
+
+	return l, nil
+}
+
+func tryJobLock(scope string, name string) (*lock.Lock, error) {
+	l, err := lock.New(jobLockPath(scope, name))
+	if err != nil {
+		return nil, err
+	}
+
+	// attempt to acquire the lock once
+	if err := l.TryLock(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
 }
 
-func releaseFileLock() {
-	if err := flock.Unlock(); err != nil {
-		log.WithError(err).Fatalf("Failed releasing file lock for %q", flagLockFile)
+func releaseJobLock(l *lock.Lock) {
+	if err := l.Unlock(); err != nil {
+		log.WithError(err).Errorf("Failed releasing lock for %q", l.Path)
 	}
 }
 
+func jobLockPath(scope string, name string) string {
+	// crop.lock -> crop_uploader_name.lock
+	ext := filepath.Ext(flagLockFile)
+	name = lockNameReplacer.ReplaceAllString(strings.ToLower(name), "_")
+
+	return fmt.Sprintf("%s_%s_%s%s", strings.TrimSuffix(flagLockFile, ext), scope, name, ext)
+}
+
 func showUsing() {
 	// show app info
 	log.Infof("Using %s = %s (%s@%s)", stringutils.LeftJust("VERSION", " ", 10),
@@ -160,3 +207,14 @@ func showUsing() {
 	cache.ShowUsing()
 	log.Info("------------------")
 }
+
+func serveMetrics(ctx context.Context) {
+	listen := config.Get().Metrics.Listen
+	if listen == "" {
+		return
+	}
+
+	if err := metrics.Serve(ctx, listen); err != nil {
+		log.WithError(err).Errorf("Failed serving metrics on: %q", listen)
+	}
+}
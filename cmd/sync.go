@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/dustin/go-humanize"
 	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/metrics"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/l3uddz/crop/syncer"
 	"github.com/pkg/errors"
@@ -30,23 +32,31 @@ var syncCmd = &cobra.Command{
 		// init core
 		initCore(true)
 		defer cache.Close()
-		defer releaseFileLock()
+
+		ctx := cmd.Context()
+		serveMetrics(ctx)
 
 		// create workers
 		var wg sync.WaitGroup
-		jobs := make(chan *syncer.Syncer, len(config.Config.Syncer))
+		jobs := make(chan *syncer.Syncer, len(config.Get().Syncer))
 
 		for w := 1; w <= flagParallelism; w++ {
 			wg.Add(1)
-			go worker(&wg, jobs)
+			go worker(ctx, &wg, jobs)
 		}
 
 		// iterate syncer's
 		started := time.Now().UTC()
 
-		for _, syncerConfig := range config.Config.Syncer {
+		for _, syncerConfig := range config.Get().Syncer {
 			syncerConfig := syncerConfig
 
+			// stop when interrupted
+			if ctx.Err() != nil {
+				log.Warn("Interrupted, skipping remaining syncer(s)")
+				break
+			}
+
 			slog := log.WithField("syncer", syncerConfig.Name)
 
 			// skip disabled syncer(s)
@@ -62,39 +72,11 @@ var syncCmd = &cobra.Command{
 			}
 
 			// create syncer
-			syncr, err := syncer.New(config.Config, &syncerConfig, syncerConfig.Name, flagParallelism)
-			if err != nil {
-				slog.WithError(err).Error("Failed initializing syncer, skipping...")
+			syncr := prepareSyncer(config.Get(), &syncerConfig, flagParallelism)
+			if syncr == nil {
 				continue
 			}
 
-			serviceAccountCount := syncr.RemoteServiceAccountFiles.ServiceAccountsCount()
-			if serviceAccountCount > 0 {
-				syncr.Log.WithField("found_files", serviceAccountCount).Info("Loaded service accounts")
-			} else {
-				// no service accounts were loaded
-				// check to see if any of the copy or sync remote(s) are banned
-				banned, expiry := rclone.AnyRemotesBanned(syncr.Config.Remotes.Copy)
-				if banned && !expiry.IsZero() {
-					// one of the copy remotes is banned, abort
-					syncr.Log.WithFields(logrus.Fields{
-						"expires_time": expiry,
-						"expires_in":   humanize.Time(expiry),
-					}).Warn("Cannot proceed with sync as a copy remote is banned")
-					continue
-				}
-
-				banned, expiry = rclone.AnyRemotesBanned(syncr.Config.Remotes.Sync)
-				if banned && !expiry.IsZero() {
-					// one of the sync remotes is banned, abort
-					syncr.Log.WithFields(logrus.Fields{
-						"expires_time": expiry,
-						"expires_in":   humanize.Time(expiry),
-					}).Warn("Cannot proceed with sync as a sync remote is banned")
-					continue
-				}
-			}
-
 			// queue sync job
 			jobs <- syncr
 		}
@@ -118,20 +100,85 @@ func init() {
 	syncCmd.Flags().BoolVar(&flagNoDedupe, "no-dedupe", false, "Ignore dedupe tasks for syncer")
 }
 
-func worker(wg *sync.WaitGroup, jobs <-chan *syncer.Syncer) {
+func prepareSyncer(cfg *config.Configuration, syncerConfig *config.SyncerConfig, parallelism int) *syncer.Syncer {
+	log := log.WithField("syncer", syncerConfig.Name)
+
+	// create syncer
+	syncr, err := syncer.New(cfg, syncerConfig, syncerConfig.Name, parallelism)
+	if err != nil {
+		log.WithError(err).Error("Failed initializing syncer, skipping...")
+		return nil
+	}
+
+	serviceAccountCount := syncr.RemoteServiceAccountFiles.ServiceAccountsCount()
+	if serviceAccountCount > 0 {
+		syncr.Log.WithField("found_files", serviceAccountCount).Info("Loaded service accounts")
+	} else {
+		// no service accounts were loaded
+		// check to see if any of the copy or sync remote(s) are banned
+		banned, expiry := rclone.AnyRemotesBanned(syncr.Config.Remotes.Copy)
+		if banned && !expiry.IsZero() {
+			// one of the copy remotes is banned, abort
+			syncr.Log.WithFields(logrus.Fields{
+				"expires_time": expiry,
+				"expires_in":   humanize.Time(expiry),
+			}).Warn("Cannot proceed with sync as a copy remote is banned")
+			notifyBanned(lockScopeSyncer, syncr.Name, "Cannot proceed with sync as a copy remote is banned", expiry)
+			return nil
+		}
+
+		banned, expiry = rclone.AnyRemotesBanned(syncr.Config.Remotes.Sync)
+		if banned && !expiry.IsZero() {
+			// one of the sync remotes is banned, abort
+			syncr.Log.WithFields(logrus.Fields{
+				"expires_time": expiry,
+				"expires_in":   humanize.Time(expiry),
+			}).Warn("Cannot proceed with sync as a sync remote is banned")
+			notifyBanned(lockScopeSyncer, syncr.Name, "Cannot proceed with sync as a sync remote is banned", expiry)
+			return nil
+		}
+	}
+
+	return syncr
+}
+
+func worker(ctx context.Context, wg *sync.WaitGroup, jobs <-chan *syncer.Syncer) {
 	defer wg.Done()
 
 	for j := range jobs {
+		// skip queued syncer(s) when interrupted
+		if ctx.Err() != nil {
+			continue
+		}
+
+		// acquire syncer lock
+		l, err := acquireJobLock(ctx, lockScopeSyncer, j.Name)
+		if err != nil {
+			j.Log.WithError(err).Error("Failed acquiring syncer lock, skipping...")
+			continue
+		}
+
 		// perform syncer job
-		if err := performSync(j); err != nil {
+		if err := performSync(ctx, j); err != nil {
 			j.Log.WithError(err).Error("Error occurred while running syncer, skipping...")
 		}
+
+		releaseJobLock(l)
 	}
 }
 
-func performSync(s *syncer.Syncer) error {
+func performSync(ctx context.Context, s *syncer.Syncer) (err error) {
 	s.Log.Info("Running...")
 
+	started := time.Now()
+	notifyRunStarted(lockScopeSyncer, s.Name)
+
+	defer func() {
+		metrics.ObserveRun(lockScopeSyncer, s.Name, time.Since(started), s.Stats, err)
+		notifyRunResult(lockScopeSyncer, s.Name, s.Stats, time.Since(started), err)
+		recordRun("sync", lockScopeSyncer, s.Name, started, 0, s.Stats, err)
+	}()
+
 	var liveRotateParams []string
 	if s.GlobalConfig.Rclone.LiveRotate && s.RemoteServiceAccountFiles.ServiceAccountsCount() > 0 {
 		// start web-server
@@ -148,7 +195,7 @@ func performSync(s *syncer.Syncer) error {
 	if len(s.Config.Remotes.Copy) > 0 {
 		s.Log.Info("Running copies...")
 
-		if err := s.Copy(liveRotateParams, flagDaisyChain); err != nil {
+		if err := s.Copy(ctx, liveRotateParams, flagDaisyChain); err != nil {
 			return errors.WithMessage(err, "failed performing all copies")
 		}
 
@@ -159,7 +206,7 @@ func performSync(s *syncer.Syncer) error {
 	if len(s.Config.Remotes.Sync) > 0 {
 		s.Log.Info("Running syncs...")
 
-		if err := s.Sync(liveRotateParams, flagDaisyChain); err != nil {
+		if err := s.Sync(ctx, liveRotateParams, flagDaisyChain); err != nil {
 			return errors.WithMessage(err, "failed performing all syncs")
 		}
 
@@ -170,7 +217,7 @@ func performSync(s *syncer.Syncer) error {
 	if len(s.Config.Remotes.MoveServerSide) > 0 {
 		s.Log.Info("Running move server-sides...")
 
-		if err := s.Move(nil); err != nil {
+		if err := s.Move(ctx, nil); err != nil {
 			return errors.WithMessage(err, "failed performing server-side moves")
 		}
 
@@ -181,13 +228,19 @@ func performSync(s *syncer.Syncer) error {
 	if !flagNoDedupe && len(s.Config.Remotes.Dedupe) > 0 {
 		s.Log.Info("Running dedupes...")
 
-		if err := s.Dedupe(nil); err != nil {
+		if err := s.Dedupe(ctx, nil); err != nil {
 			return errors.WithMessage(err, "failed performing all dedupes")
 		}
 
 		s.Log.Info("Finished dedupes!")
 	}
 
-	s.Log.Info("Finished!")
+	s.Log.WithFields(logrus.Fields{
+		"transferred": humanize.IBytes(uint64(s.Stats.Bytes)),
+		"transfers":   s.Stats.Transfers,
+		"deletes":     s.Stats.Deletes,
+		"errors":      s.Stats.Errors,
+		"elapsed":     s.Stats.Elapsed.Round(time.Second),
+	}).Info("Finished!")
 	return nil
 }
@@ -19,7 +19,6 @@ var updateCmd = &cobra.Command{
 		// init core
 		initCore(false)
 		defer cache.Close()
-		defer releaseFileLock()
 
 		// parse current version
 		v, err := semver.Parse(runtime.Version)
@@ -1,14 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/dustin/go-humanize"
 	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/metrics"
+	"github.com/l3uddz/crop/notify"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/l3uddz/crop/uploader"
+	"github.com/l3uddz/crop/uploader/checker"
 	"github.com/pkg/errors"
-	"github.com/shirou/gopsutil/disk"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"strings"
@@ -28,12 +31,22 @@ var uploadCmd = &cobra.Command{
 		// init core
 		initCore(true)
 		defer cache.Close()
-		defer releaseFileLock()
+
+		ctx := cmd.Context()
+		serveMetrics(ctx)
 
 		// iterate uploader's
 		started := time.Now().UTC()
 
-		for _, uploaderConfig := range config.Config.Uploader {
+		for _, uploaderConfig := range config.Get().Uploader {
+			uploaderConfig := uploaderConfig
+
+			// stop when interrupted
+			if ctx.Err() != nil {
+				log.Warn("Interrupted, skipping remaining uploader(s)")
+				break
+			}
+
 			log := log.WithField("uploader", uploaderConfig.Name)
 
 			// skip disabled uploader(s)
@@ -48,108 +61,16 @@ var uploadCmd = &cobra.Command{
 				continue
 			}
 
-			// create uploader
-			upload, err := uploader.New(config.Config, &uploaderConfig, uploaderConfig.Name)
+			// acquire uploader lock
+			l, err := acquireJobLock(ctx, lockScopeUploader, uploaderConfig.Name)
 			if err != nil {
-				log.WithError(err).Error("Failed initializing uploader, skipping...")
-				continue
-			}
-
-			serviceAccountCount := upload.RemoteServiceAccountFiles.ServiceAccountsCount()
-			if serviceAccountCount > 0 {
-				upload.Log.WithField("found_files", serviceAccountCount).Info("Loaded service accounts")
-			} else {
-				// no service accounts were loaded
-				// check to see if any of the copy or move remote(s) are banned
-				banned, expiry := rclone.AnyRemotesBanned(upload.Config.Remotes.Copy)
-				if banned && !expiry.IsZero() {
-					// one of the copy remotes is banned, abort
-					upload.Log.WithFields(logrus.Fields{
-						"expires_time": expiry,
-						"expires_in":   humanize.Time(expiry),
-					}).Warn("Cannot proceed with upload as a copy remote is banned")
-					continue
-				}
-
-				banned, expiry = rclone.AnyRemotesBanned([]string{upload.Config.Remotes.Move})
-				if banned && !expiry.IsZero() {
-					// the move remote is banned, abort
-					upload.Log.WithFields(logrus.Fields{
-						"expires_time": expiry,
-						"expires_in":   humanize.Time(expiry),
-					}).Warn("Cannot proceed with upload as the move remote is banned")
-					continue
-				}
-			}
-
-			log.Info("Uploader commencing...")
-
-			// refresh details about files to upload
-			if err := upload.RefreshLocalFiles(); err != nil {
-				upload.Log.WithError(err).Error("Failed refreshing details of files to upload")
+				log.WithError(err).Error("Failed acquiring uploader lock, skipping...")
 				continue
 			}
 
-			if len(upload.LocalFiles) == 0 {
-				// there are no files to upload
-				upload.Log.Info("There were no files found, skipping...")
-				continue
-			}
-
-			// check if upload criteria met
-			forced := false
-
-			if !flagNoCheck {
-				// no check was not enabled
-				res, err := upload.Check()
-				if err != nil {
-					upload.Log.WithError(err).Error("Failed checking if uploader check conditions met, skipping...")
-					continue
-				}
-
-				if !res.Passed {
-					// get free disk space
-					freeDiskSpace := "Unknown"
-					du, err := disk.Usage(upload.Config.LocalFolder)
-					if err == nil {
-						freeDiskSpace = humanize.IBytes(du.Free)
-					}
-
-					// check available disk space
-					switch {
-					case err != nil && upload.Config.Check.MinFreeSpace > 0:
-						// error checking free space
-						upload.Log.WithError(err).Errorf("Failed checking available free space for: %q",
-							upload.Config.LocalFolder)
-					case err == nil && du.Free < upload.Config.Check.MinFreeSpace:
-						// free space has gone below the free space threshold
-						forced = true
-						upload.Log.WithFields(logrus.Fields{
-							"until":     res.Info,
-							"free_disk": freeDiskSpace,
-						}).Infof("Upload conditions not met, however, proceeding as free space below %s",
-							humanize.IBytes(upload.Config.Check.MinFreeSpace))
-					default:
-						break
-					}
-
-					if !forced {
-						upload.Log.WithFields(logrus.Fields{
-							"until":     res.Info,
-							"free_disk": freeDiskSpace,
-						}).Info("Upload conditions not met, skipping...")
-						continue
-					}
-
-					// the upload was forced as min_free_size was met
-				}
-			}
-
-			// perform upload
-			if err := performUpload(upload, forced); err != nil {
-				upload.Log.WithError(err).Error("Error occurred while running uploader, skipping...")
-				continue
-			}
+			// process uploader
+			processUploader(ctx, config.Get(), &uploaderConfig)
+			releaseJobLock(l)
 		}
 
 		log.Infof("Finished in: %v", humanize.RelTime(started, time.Now().UTC(), "", ""))
@@ -165,9 +86,110 @@ func init() {
 	uploadCmd.Flags().BoolVar(&flagNoDedupe, "no-dedupe", false, "Ignore dedupe tasks for uploader")
 }
 
-func performUpload(u *uploader.Uploader, forced bool) error {
+func processUploader(ctx context.Context, cfg *config.Configuration, uploaderConfig *config.UploaderConfig) {
+	log := log.WithField("uploader", uploaderConfig.Name)
+
+	// create uploader
+	upload, err := uploader.New(cfg, uploaderConfig, uploaderConfig.Name)
+	if err != nil {
+		log.WithError(err).Error("Failed initializing uploader, skipping...")
+		return
+	}
+
+	serviceAccountCount := upload.RemoteServiceAccountFiles.ServiceAccountsCount()
+	if serviceAccountCount > 0 {
+		upload.Log.WithField("found_files", serviceAccountCount).Info("Loaded service accounts")
+	} else {
+		// no service accounts were loaded
+		// check to see if any of the copy or move remote(s) are banned
+		banned, expiry := rclone.AnyRemotesBanned(upload.Config.Remotes.Copy)
+		if banned && !expiry.IsZero() {
+			// one of the copy remotes is banned, abort
+			upload.Log.WithFields(logrus.Fields{
+				"expires_time": expiry,
+				"expires_in":   humanize.Time(expiry),
+			}).Warn("Cannot proceed with upload as a copy remote is banned")
+			notifyBanned(lockScopeUploader, upload.Name, "Cannot proceed with upload as a copy remote is banned", expiry)
+			return
+		}
+
+		banned, expiry = rclone.AnyRemotesBanned([]string{upload.Config.Remotes.Move})
+		if banned && !expiry.IsZero() {
+			// the move remote is banned, abort
+			upload.Log.WithFields(logrus.Fields{
+				"expires_time": expiry,
+				"expires_in":   humanize.Time(expiry),
+			}).Warn("Cannot proceed with upload as the move remote is banned")
+			notifyBanned(lockScopeUploader, upload.Name, "Cannot proceed with upload as the move remote is banned", expiry)
+			return
+		}
+	}
+
+	log.Info("Uploader commencing...")
+
+	// refresh details about files to upload
+	if err := upload.RefreshLocalFiles(ctx); err != nil {
+		upload.Log.WithError(err).Error("Failed refreshing details of files to upload")
+		return
+	}
+
+	if len(upload.LocalFiles) == 0 {
+		// there are no files to upload
+		upload.Log.Info("There were no files found, skipping...")
+		return
+	}
+
+	// check if upload criteria met
+	var res *checker.Result
+
+	if !flagNoCheck {
+		// no check was not enabled
+		var err error
+
+		res, err = upload.Check()
+		if err != nil {
+			upload.Log.WithError(err).Error("Failed checking if uploader check conditions met, skipping...")
+			return
+		}
+
+		if !res.Passed {
+			upload.Log.WithField("until", res.Info).Info("Upload conditions not met, skipping...")
+			return
+		}
+
+		if fs := res.PassedRule("free_space"); fs != nil {
+			// free space has gone below the free space threshold
+			notify.Send(&notify.Notification{
+				Event:   notify.EventFreeSpaceForcedUpload,
+				Scope:   lockScopeUploader,
+				Name:    upload.Name,
+				Message: fmt.Sprintf("Proceeding with upload as free space below %s", humanize.IBytes(fs.Limit)),
+				Fields: map[string]interface{}{
+					"check":     res.Info,
+					"free_disk": fs.Info,
+				},
+			})
+		}
+	}
+
+	// perform upload
+	if err := performUpload(ctx, upload, res); err != nil {
+		upload.Log.WithError(err).Error("Error occurred while running uploader, skipping...")
+	}
+}
+
+func performUpload(ctx context.Context, u *uploader.Uploader, res *checker.Result) (err error) {
 	u.Log.Info("Running...")
 
+	started := time.Now()
+	notifyRunStarted(lockScopeUploader, u.Name)
+
+	defer func() {
+		metrics.ObserveRun(lockScopeUploader, u.Name, time.Since(started), u.Stats, err)
+		notifyRunResult(lockScopeUploader, u.Name, u.Stats, time.Since(started), err)
+		recordRun("upload", lockScopeUploader, u.Name, started, len(u.LocalFiles), u.Stats, err)
+	}()
+
 	var liveRotateParams []string
 
 	if u.GlobalConfig.Rclone.LiveRotate && u.RemoteServiceAccountFiles.ServiceAccountsCount() > 0 {
@@ -183,23 +205,46 @@ func performUpload(u *uploader.Uploader, forced bool) error {
 
 	/* Cleans */
 	if u.Config.Hidden.Enabled {
-		err := performClean(u)
+		err := performClean(ctx, u)
 		if err != nil {
 			return errors.Wrap(err, "failed clearing remotes")
 		}
 	}
 
 	/* Generate Additional Rclone Params */
-	var additionalRcloneParams []string
+	// files still being written are excluded first
+	additionalRcloneParams, cleanupSkipped, err := u.SkipRcloneParams()
+	if err != nil {
+		return errors.WithMessage(err, "failed generating exclude-from list")
+	}
+	defer cleanupSkipped()
+
+	// an upload forced by low free space uploads every file, without the check's params
+	forced := res != nil && res.AllFiles && res.PassedRule("free_space") != nil
+
+	if (!flagNoCheck || u.Config.Check.Forced) && !forced {
+		// if no-check is false (default) or check is forced via config, include check params
+		checkParams := u.CheckRcloneParams(res)
+
+		if u.Config.Check.FilesFrom {
+			// only upload the files accepted by the checker
+			params, cleanup, err := u.FilesFromRcloneParams(res)
+			if err != nil {
+				return errors.WithMessage(err, "failed generating files-from list")
+			}
+			defer cleanup()
+
+			if len(params) == 0 {
+				u.Log.Info("There were no files eligible for upload, skipping...")
+				return nil
+			}
 
-	switch forced {
-	case false:
-		if !flagNoCheck || u.Config.Check.Forced {
-			// if no-check is false (default) or check is forced via config, include check params
-			additionalRcloneParams = u.CheckRcloneParams()
+			// the list replaces the check's filters
+			additionalRcloneParams = append(additionalRcloneParams, params...)
+			additionalRcloneParams = append(additionalRcloneParams, checkParams.Flags...)
+		} else {
+			additionalRcloneParams = append(additionalRcloneParams, checkParams.All()...)
 		}
-	default:
-		break
 	}
 
 	// add live rotate params set
@@ -211,7 +256,7 @@ func performUpload(u *uploader.Uploader, forced bool) error {
 	if len(u.Config.Remotes.Copy) > 0 {
 		u.Log.Info("Running copies...")
 
-		if err := u.Copy(additionalRcloneParams); err != nil {
+		if err := u.Copy(ctx, additionalRcloneParams); err != nil {
 			return errors.WithMessage(err, "failed performing all copies")
 		}
 
@@ -222,7 +267,7 @@ func performUpload(u *uploader.Uploader, forced bool) error {
 	if len(u.Config.Remotes.Move) > 0 {
 		u.Log.Info("Running move...")
 
-		if err := u.Move(false, additionalRcloneParams); err != nil {
+		if err := u.Move(ctx, false, additionalRcloneParams); err != nil {
 			return errors.WithMessage(err, "failed performing move")
 		}
 
@@ -233,7 +278,7 @@ func performUpload(u *uploader.Uploader, forced bool) error {
 	if len(u.Config.Remotes.MoveServerSide) > 0 {
 		u.Log.Info("Running move server-sides...")
 
-		if err := u.Move(true, nil); err != nil {
+		if err := u.Move(ctx, true, nil); err != nil {
 			return errors.WithMessage(err, "failed performing server-side moves")
 		}
 
@@ -244,13 +289,19 @@ func performUpload(u *uploader.Uploader, forced bool) error {
 	if !flagNoDedupe && len(u.Config.Remotes.Dedupe) > 0 {
 		u.Log.Info("Running dedupes...")
 
-		if err := u.Dedupe(nil); err != nil {
+		if err := u.Dedupe(ctx, nil); err != nil {
 			return errors.WithMessage(err, "failed performing dedupes")
 		}
 
 		u.Log.Info("Finished dupes!")
 	}
 
-	u.Log.Info("Finished!")
+	u.Log.WithFields(logrus.Fields{
+		"transferred": humanize.IBytes(uint64(u.Stats.Bytes)),
+		"transfers":   u.Stats.Transfers,
+		"deletes":     u.Stats.Deletes,
+		"errors":      u.Stats.Errors,
+		"elapsed":     u.Stats.Elapsed.Round(time.Second),
+	}).Info("Finished!")
 	return nil
 }
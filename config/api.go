@@ -0,0 +1,6 @@
+package config
+
+type APIConfig struct {
+	Listen string `yaml:"listen"`
+	Token  string `yaml:"token"`
+}
@@ -4,24 +4,30 @@ import (
 	"fmt"
 	"github.com/l3uddz/crop/logger"
 	"github.com/l3uddz/crop/stringutils"
-	"gopkg.in/yaml.v2"
-	"io/ioutil"
+	"strings"
+	"sync/atomic"
 )
 
 type Configuration struct {
 	Rclone   RcloneConfig
 	Uploader []UploaderConfig
 	Syncer   []SyncerConfig
+	Metrics  MetricsConfig
+	History  HistoryConfig
+	API      APIConfig `yaml:"api"`
+
+	Notifications []NotificationConfig
 }
 
 /* Vars */
 
 var (
-	Config *Configuration
-
 	// internal
 	cfgPath = ""
 	log     = logger.GetLogger("cfg")
+
+	// swapped as a whole when the config is reloaded
+	current atomic.Value
 )
 
 /* Public */
@@ -30,20 +36,55 @@ func Init(configFilePath string) error {
 	// set package variables
 	cfgPath = configFilePath
 
-	// read config file
-	b, err := ioutil.ReadFile(configFilePath)
+	cfg, err := Load(configFilePath)
 	if err != nil {
-		return fmt.Errorf("failed reading config file: %w", err)
+		return err
 	}
 
-	// decode config file
-	if err := yaml.Unmarshal(b, &Config); err != nil {
-		return fmt.Errorf("failed decoding config file: %w", err)
+	Set(cfg)
+
+	return nil
+}
+
+// Get returns the current config, or nil when it has not been loaded.
+func Get() *Configuration {
+	if cfg, ok := current.Load().(*Configuration); ok {
+		return cfg
 	}
 
 	return nil
 }
 
+// Set replaces the current config, running task(s) keep the config they were started with.
+func Set(cfg *Configuration) {
+	current.Store(cfg)
+}
+
+func Load(configFilePath string) (*Configuration, error) {
+	// load config file (and overlays)
+	doc, err := load(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode config file
+	cfg := new(Configuration)
+	errs, err := doc.decode(cfg)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("failed decoding config file: %w", err)
+	case len(errs) > 0:
+		msgs := make([]string, 0, len(errs))
+		for _, e := range errs {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", e.File, e))
+		}
+
+		return nil, fmt.Errorf("failed decoding config file:\n  %s", strings.Join(msgs, "\n  "))
+	}
+
+	return cfg, nil
+}
+
 func ShowUsing() {
 	log.Infof("Using %s = %q", stringutils.LeftJust("CONFIG", " ", 10), cfgPath)
 }
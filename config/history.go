@@ -0,0 +1,5 @@
+package config
+
+type HistoryConfig struct {
+	Retention string `yaml:"retention"`
+}
@@ -0,0 +1,442 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	includeTag      = "!include"
+	filePrefix      = "file://"
+	overlayFolder   = "config.d"
+	maxIncludeDepth = 10
+)
+
+/* Struct */
+
+type document struct {
+	root  *yaml.Node
+	path  string
+	files map[*yaml.Node]string
+
+	// every file read, including overlays, includes & file references
+	sources map[string]bool
+}
+
+/* Var */
+
+var (
+	// ${VAR} or ${VAR:-default}
+	envRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?}`)
+)
+
+/* Private */
+
+func load(configFilePath string) (*document, error) {
+	doc := newDocument(configFilePath)
+	if err := doc.load(); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func newDocument(configFilePath string) *document {
+	return &document{
+		path:    configFilePath,
+		files:   make(map[*yaml.Node]string),
+		sources: make(map[string]bool),
+	}
+}
+
+func (d *document) load() error {
+	// load base config
+	root, err := d.read(d.path, 0)
+	if err != nil {
+		return err
+	}
+	d.root = root
+
+	// merge overlay config(s)
+	overlays, err := filepath.Glob(filepath.Join(filepath.Dir(d.path), overlayFolder, "*.y*ml"))
+	if err != nil {
+		return fmt.Errorf("failed finding overlay configs: %w", err)
+	}
+
+	sort.Strings(overlays)
+
+	for _, overlay := range overlays {
+		log.Debugf("Merging overlay config: %q", overlay)
+
+		n, err := d.read(overlay, 0)
+		if err != nil {
+			return err
+		}
+
+		if err := d.merge(d.root, n, overlay); err != nil {
+			return fmt.Errorf("failed merging overlay config %q: %w", overlay, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *document) read(path string, depth int) (*yaml.Node, error) {
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("too many nested includes at: %q", path)
+	}
+
+	d.sources[filepath.Clean(path)] = true
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading config file: %w", err)
+	}
+
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// an empty file
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		root = n.Content[0]
+	}
+
+	d.files[root] = path
+
+	// resolve variables & includes
+	if err := d.resolve(root, filepath.Dir(path), depth); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return root, nil
+}
+
+func (d *document) resolve(n *yaml.Node, dir string, depth int) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		value, err := expandEnv(n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+
+		if value != n.Value && n.Style == 0 && n.Tag != includeTag {
+			// re-determine the type of the expanded value (e.g. ${PORT} -> int)
+			n.Tag = ""
+		}
+		n.Value = value
+
+		switch {
+		case n.Tag == includeTag:
+			// replace this node with the included document
+			path := n.Value
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			inc, err := d.read(path, depth+1)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n.Line, err)
+			}
+
+			*n = *inc
+			d.files[n] = path
+		case strings.HasPrefix(n.Value, filePrefix):
+			// replace this value with the contents of a file (e.g. a secret)
+			path := strings.TrimPrefix(n.Value, filePrefix)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			d.sources[filepath.Clean(path)] = true
+
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("line %d: failed reading file reference: %w", n.Line, err)
+			}
+
+			n.Value = strings.TrimRight(string(b), "\r\n")
+			n.Tag = "!!str"
+		}
+	default:
+		for _, c := range n.Content {
+			if err := d.resolve(c, dir, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *document) decode(cfg *Configuration) ([]ValidationError, error) {
+	// unknown keys are an error
+	if errs := d.checkKnownFields(d.root, reflect.TypeOf(cfg).Elem(), nil, d.path); len(errs) > 0 {
+		return errs, nil
+	}
+
+	return nil, d.root.Decode(cfg)
+}
+
+func (d *document) checkKnownFields(n *yaml.Node, t reflect.Type, path []interface{}, file string) []ValidationError {
+	if f, ok := d.files[n]; ok {
+		file = f
+	}
+
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	errs := make([]ValidationError, 0)
+
+	switch {
+	case t.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+
+			// keys merged from an overlay belong to the overlay
+			keyFile := file
+			if f, ok := d.files[value]; ok {
+				keyFile = f
+			}
+
+			f, ok := fields[key.Value]
+			if !ok {
+				errs = append(errs, ValidationError{
+					File:    keyFile,
+					Line:    key.Line,
+					Path:    formatPath(path),
+					Message: fmt.Sprintf("field %s not found in type %v", key.Value, t),
+				})
+				continue
+			}
+
+			errs = append(errs, d.checkKnownFields(value, f.Type, append(path, key.Value), file)...)
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && n.Kind == yaml.SequenceNode:
+		for i, c := range n.Content {
+			errs = append(errs, d.checkKnownFields(c, t.Elem(), append(path, i), file)...)
+		}
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			errs = append(errs, d.checkKnownFields(n.Content[i+1], t.Elem(), append(path, n.Content[i].Value),
+				file)...)
+		}
+	}
+
+	return errs
+}
+
+func (d *document) position(path []interface{}) (string, int) {
+	n := d.root
+	file := d.files[n]
+	line := 0
+
+	for _, key := range path {
+		var next *yaml.Node
+
+		switch k := key.(type) {
+		case int:
+			if n.Kind == yaml.SequenceNode && k < len(n.Content) {
+				next = n.Content[k]
+				line = next.Line
+			}
+		case string:
+			if n.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(n.Content); i += 2 {
+					if n.Content[i].Value == k {
+						line = n.Content[i].Line
+						next = n.Content[i+1]
+						break
+					}
+				}
+			}
+		}
+
+		if next == nil {
+			// report the closest parent that exists
+			break
+		}
+
+		if f, ok := d.files[next]; ok {
+			file = f
+		}
+
+		n = next
+	}
+
+	return file, line
+}
+
+func expandEnv(value string) (string, error) {
+	var err error
+
+	expanded := envRegex.ReplaceAllStringFunc(value, func(s string) string {
+		m := envRegex.FindStringSubmatch(s)
+		v, ok := os.LookupEnv(m[1])
+
+		switch {
+		case strings.Contains(s, ":-"):
+			// the default is used for unset & empty variables
+			if v == "" {
+				return m[2]
+			}
+			return v
+		case ok:
+			return v
+		}
+
+		if err == nil {
+			err = fmt.Errorf("environment variable %s is not set", m[1])
+		}
+		return s
+	})
+
+	return expanded, err
+}
+
+func (d *document) merge(dst *yaml.Node, src *yaml.Node, file string) error {
+	if dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", src.Line)
+	}
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		// find existing key
+		existing := -1
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				existing = j + 1
+				break
+			}
+		}
+
+		switch {
+		case existing < 0:
+			// new key
+			d.graft(value, file)
+			dst.Content = append(dst.Content, key, value)
+		case dst.Content[existing].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if err := d.merge(dst.Content[existing], value, file); err != nil {
+				return err
+			}
+		case dst.Content[existing].Kind == yaml.SequenceNode && isNamedSequence(value):
+			// uploader(s) & syncer(s) are merged by name
+			if err := d.mergeNamed(dst.Content[existing], value, file); err != nil {
+				return err
+			}
+		default:
+			d.graft(value, file)
+			dst.Content[existing] = value
+		}
+	}
+
+	return nil
+}
+
+func (d *document) mergeNamed(dst *yaml.Node, src *yaml.Node, file string) error {
+	for _, item := range src.Content {
+		name := mappingValue(item, "name")
+
+		found := false
+		for _, existing := range dst.Content {
+			if existing.Kind == yaml.MappingNode && mappingValue(existing, "name") == name {
+				if err := d.merge(existing, item, file); err != nil {
+					return err
+				}
+
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			d.graft(item, file)
+			dst.Content = append(dst.Content, item)
+		}
+	}
+
+	return nil
+}
+
+func (d *document) graft(n *yaml.Node, file string) {
+	// nodes from another file keep reporting their own file (unless included from elsewhere)
+	if _, ok := d.files[n]; !ok {
+		d.files[n] = file
+	}
+}
+
+func isNamedSequence(n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		return false
+	}
+
+	for _, item := range n.Content {
+		if item.Kind != yaml.MappingNode || mappingValue(item, "name") == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func mappingValue(n *yaml.Node, key string) string {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1].Value
+		}
+	}
+
+	return ""
+}
+
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("yaml"), ",")
+		if f.Anonymous && len(tag) > 1 && tag[1] == "inline" {
+			// fields of inlined structs belong to the parent
+			for name, inlined := range yamlFields(f.Type) {
+				fields[name] = inlined
+			}
+			continue
+		}
+
+		name := tag[0]
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		fields[name] = f
+	}
+
+	return fields
+}
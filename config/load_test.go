@@ -0,0 +1,266 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	setEnv(t, "CROP_TEST_SET", "value")
+	setEnv(t, "CROP_TEST_EMPTY", "")
+	unsetEnv(t, "CROP_TEST_UNSET")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"no variables", "plain value", "plain value", false},
+		{"set", "${CROP_TEST_SET}", "value", false},
+		{"within a value", "/mnt/${CROP_TEST_SET}/media", "/mnt/value/media", false},
+		{"multiple", "${CROP_TEST_SET}-${CROP_TEST_SET}", "value-value", false},
+		{"empty", "${CROP_TEST_EMPTY}", "", false},
+		{"unset", "${CROP_TEST_UNSET}", "${CROP_TEST_UNSET}", true},
+		{"default when unset", "${CROP_TEST_UNSET:-fallback}", "fallback", false},
+		{"default when empty", "${CROP_TEST_EMPTY:-fallback}", "fallback", false},
+		{"default not used when set", "${CROP_TEST_SET:-fallback}", "value", false},
+		{"empty default", "${CROP_TEST_UNSET:-}", "", false},
+		{"unbraced is ignored", "$CROP_TEST_SET", "$CROP_TEST_SET", false},
+		{"invalid name is ignored", "${1CROP}", "${1CROP}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandEnv(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandEnv(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("expandEnv(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	setEnv(t, "CROP_TEST_LIMIT", "25")
+	setEnv(t, "CROP_TEST_FOLDER", "/mnt/local")
+	unsetEnv(t, "CROP_TEST_UNSET")
+
+	base := `
+rclone:
+  config: /opt/rclone/rclone.conf
+uploader:
+  - name: movies
+    enabled: true
+    check:
+      type: size
+      limit: 1
+    local_folder: /mnt/movies
+`
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		check   func(t *testing.T, cfg *Configuration)
+		wantErr string
+	}{
+		{
+			name: "environment variables",
+			files: map[string]string{
+				"config.yaml": `
+rclone:
+  config: ${CROP_TEST_FOLDER}/rclone.conf
+uploader:
+  - name: movies
+    check:
+      limit: ${CROP_TEST_LIMIT}
+    local_folder: "${CROP_TEST_FOLDER}"
+`,
+			},
+			check: func(t *testing.T, cfg *Configuration) {
+				expect(t, "rclone.config", cfg.Rclone.Config, "/mnt/local/rclone.conf")
+				expect(t, "check.limit", cfg.Uploader[0].Check.Limit, uint64(25))
+				expect(t, "local_folder", cfg.Uploader[0].LocalFolder, "/mnt/local")
+			},
+		},
+		{
+			name: "unset environment variable",
+			files: map[string]string{
+				"config.yaml": "rclone:\n  config: ${CROP_TEST_UNSET}\n",
+			},
+			wantErr: "CROP_TEST_UNSET is not set",
+		},
+		{
+			name: "include",
+			files: map[string]string{
+				"config.yaml": "uploader: !include includes/uploader.yaml\n",
+				"includes/uploader.yaml": `
+- name: tv
+  check:
+    limit: ${CROP_TEST_LIMIT}
+  remotes:
+    move: !include remote.yaml
+`,
+				"includes/remote.yaml": "gdrive:/Media\n",
+			},
+			check: func(t *testing.T, cfg *Configuration) {
+				expect(t, "uploaders", len(cfg.Uploader), 1)
+				expect(t, "name", cfg.Uploader[0].Name, "tv")
+				expect(t, "check.limit", cfg.Uploader[0].Check.Limit, uint64(25))
+				expect(t, "remotes.move", cfg.Uploader[0].Remotes.Move, "gdrive:/Media")
+			},
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"config.yaml": "uploader: !include missing.yaml\n",
+			},
+			wantErr: "failed reading config file",
+		},
+		{
+			name: "recursive include",
+			files: map[string]string{
+				"config.yaml": "rclone: !include rclone.yaml\n",
+				"rclone.yaml": "config: !include rclone.yaml\n",
+			},
+			wantErr: "too many nested includes",
+		},
+		{
+			name: "file reference",
+			files: map[string]string{
+				"config.yaml":    "rclone:\n  config: file://secrets/rclone\n",
+				"secrets/rclone": "/opt/rclone/rclone.conf\n",
+			},
+			check: func(t *testing.T, cfg *Configuration) {
+				expect(t, "rclone.config", cfg.Rclone.Config, "/opt/rclone/rclone.conf")
+			},
+		},
+		{
+			name: "overlays",
+			files: map[string]string{
+				"config.yaml": base,
+				"config.d/10-movies.yaml": `
+uploader:
+  - name: movies
+    check:
+      limit: 50
+`,
+				"config.d/20-tv.yaml": `
+rclone:
+  dry_run: true
+uploader:
+  - name: tv
+    local_folder: /mnt/tv
+`,
+				"config.d/ignored.txt": "rclone: invalid",
+			},
+			check: func(t *testing.T, cfg *Configuration) {
+				expect(t, "rclone.config", cfg.Rclone.Config, "/opt/rclone/rclone.conf")
+				expect(t, "rclone.dry_run", cfg.Rclone.DryRun, true)
+				expect(t, "uploaders", len(cfg.Uploader), 2)
+				expect(t, "movies.enabled", cfg.Uploader[0].Enabled, true)
+				expect(t, "movies.check.type", cfg.Uploader[0].Check.Type, "size")
+				expect(t, "movies.check.limit", cfg.Uploader[0].Check.Limit, uint64(50))
+				expect(t, "movies.local_folder", cfg.Uploader[0].LocalFolder, "/mnt/movies")
+				expect(t, "tv.local_folder", cfg.Uploader[1].LocalFolder, "/mnt/tv")
+			},
+		},
+		{
+			name: "overlays applied in order",
+			files: map[string]string{
+				"config.yaml":     base,
+				"config.d/b.yaml": "rclone:\n  config: /b.conf\n",
+				"config.d/a.yaml": "rclone:\n  config: /a.conf\n",
+				"config.d/c.yml":  "rclone:\n  stats: 1m\n",
+			},
+			check: func(t *testing.T, cfg *Configuration) {
+				expect(t, "rclone.config", cfg.Rclone.Config, "/b.conf")
+				expect(t, "rclone.stats", cfg.Rclone.Stats, "1m")
+			},
+		},
+		{
+			name: "unknown key in overlay",
+			files: map[string]string{
+				"config.yaml":     base,
+				"config.d/a.yaml": "rclone:\n  unknown: true\n",
+			},
+			wantErr: "config.d/a.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := Load(filepath.Join(dir, "config.yaml"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func expect(t *testing.T, name string, got interface{}, want interface{}) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func setEnv(t *testing.T, key string, value string) {
+	t.Helper()
+
+	prev, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, prev)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+
+	prev, ok := os.LookupEnv(key)
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, prev)
+		}
+	})
+}
@@ -0,0 +1,5 @@
+package config
+
+type MetricsConfig struct {
+	Listen string `yaml:"listen"`
+}
@@ -0,0 +1,19 @@
+package config
+
+type NotificationConfig struct {
+	Name   string
+	Type   string
+	Events []string
+
+	// webhook, discord, slack, ntfy & gotify
+	URL   string
+	Token string
+
+	// smtp
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
@@ -1,13 +1,45 @@
 package config
 
 type RcloneConfig struct {
-	Path                  string                  `yaml:"path"`
-	Config                string                  `yaml:"config"`
-	Stats                 string                  `yaml:"stats"`
-	LiveRotate            bool                    `yaml:"live_rotate"`
-	DryRun                bool                    `yaml:"dry_run"`
-	ServiceAccountRemotes map[string][]string     `yaml:"service_account_remotes"`
-	GlobalParams          map[string]RcloneParams `yaml:"global_params"`
+	Path                  string                            `yaml:"path"`
+	Backend               string                            `yaml:"backend"`
+	Config                string                            `yaml:"config"`
+	Stats                 string                            `yaml:"stats"`
+	Timeout               string                            `yaml:"timeout"`
+	GracePeriod           string                            `yaml:"grace_period"`
+	LiveRotate            bool                              `yaml:"live_rotate"`
+	DryRun                bool                              `yaml:"dry_run"`
+	ServiceAccountRemotes map[string][]ServiceAccountRemote `yaml:"service_account_remotes"`
+	GlobalParams          map[string]RcloneParams           `yaml:"global_params"`
+	Ban                   RcloneBanConfig                   `yaml:"ban"`
+	Quota                 RcloneQuotaConfig                 `yaml:"quota"`
+	Rc                    RcloneRcConfig                    `yaml:"rc"`
+}
+
+type RcloneRcConfig struct {
+	URL          string `yaml:"url"`
+	User         string `yaml:"user"`
+	Pass         string `yaml:"pass"`
+	PollInterval string `yaml:"poll_interval"`
+}
+
+type RcloneQuotaConfig struct {
+	Daily   string `yaml:"daily"`
+	Reserve string `yaml:"reserve"`
+}
+
+type RcloneBanConfig struct {
+	Hours           int            `yaml:"hours"`
+	QuotaReset      bool           `yaml:"quota_reset"`
+	Escalate        bool           `yaml:"escalate"`
+	MaxHours        int            `yaml:"max_hours"`
+	Remotes         map[string]int `yaml:"remotes"`
+	ServiceAccounts map[string]int `yaml:"service_accounts"`
+}
+
+type ServiceAccountRemote struct {
+	Remote   string `yaml:"remote"`
+	Strategy string `yaml:"strategy"`
 }
 
 type RcloneServerSide struct {
@@ -22,3 +54,16 @@ type RcloneParams struct {
 	Sync           []string
 	Dedupe         []string
 }
+
+func (r *ServiceAccountRemote) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// a remote name on its own, e.g. - tv
+	var remote string
+	if err := unmarshal(&remote); err == nil {
+		r.Remote = remote
+		return nil
+	}
+
+	// a remote with options, e.g. - remote: tv
+	type plain ServiceAccountRemote
+	return unmarshal((*plain)(r))
+}
@@ -21,6 +21,7 @@ type SyncerRcloneParams struct {
 type SyncerConfig struct {
 	Name         string
 	Enabled      bool
+	Schedule     string
 	SourceRemote string `yaml:"source_remote"`
 	Remotes      SyncerRemotes
 	RcloneParams SyncerRcloneParams `yaml:"rclone_params"`
@@ -1,10 +1,22 @@
 package config
 
+type UploaderCheckRule struct {
+	Type      string
+	Limit     uint64
+	Direction string
+	Windows   []string
+	Timezone  string
+	BwLimit   string `yaml:"bwlimit"`
+}
+
 type UploaderCheck struct {
+	UploaderCheckRule `yaml:",inline"`
+
 	Forced       bool
 	MinFreeSpace uint64 `yaml:"min_free_space"`
-	Type         string
-	Limit        uint64
+	FilesFrom    bool   `yaml:"files_from"`
+	Operator     string
+	Rules        []UploaderCheckRule
 	Exclude      []string
 	Include      []string
 }
@@ -17,6 +29,12 @@ type UploaderHidden struct {
 	Workers int
 }
 
+type UploaderInProgress struct {
+	Disabled       bool
+	Suffixes       []string
+	SampleInterval string `yaml:"sample_interval"`
+}
+
 type UploaderRemotes struct {
 	Clean          []string
 	Copy           []string
@@ -39,9 +57,11 @@ type UploaderRcloneParams struct {
 type UploaderConfig struct {
 	Name         string
 	Enabled      bool
+	Schedule     string
 	Check        UploaderCheck
 	Hidden       UploaderHidden
-	LocalFolder  string `yaml:"local_folder"`
+	InProgress   UploaderInProgress `yaml:"in_progress"`
+	LocalFolder  string             `yaml:"local_folder"`
 	Remotes      UploaderRemotes
 	RcloneParams UploaderRcloneParams `yaml:"rclone_params"`
 }
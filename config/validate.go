@@ -0,0 +1,566 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/reutils"
+	"github.com/l3uddz/crop/timeutils"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* Struct */
+
+type ValidateOptions struct {
+	CheckTypes      []string
+	CheckOperators  []string
+	CheckDirections []string
+	CleanerTypes    []string
+	Strategies      []string
+	Backends        []string
+
+	NotificationTypes  []string
+	NotificationEvents []string
+}
+
+type ValidationError struct {
+	File    string
+	Line    int
+	Path    string
+	Message string
+}
+
+type validator struct {
+	opts   ValidateOptions
+	cfg    *Configuration
+	doc    *document
+	errors []ValidationError
+}
+
+/* Var */
+
+var (
+	yamlFileRegex = regexp.MustCompile(`^(\S+\.ya?ml): (.*)$`)
+	yamlLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+)
+
+/* Public */
+
+func Validate(configFilePath string, opts ValidateOptions) ([]ValidationError, error) {
+	v := &validator{
+		opts: opts,
+		cfg:  new(Configuration),
+	}
+
+	// load config file (and overlays)
+	doc, err := load(configFilePath)
+	if err != nil {
+		v.addDecodeErrors(configFilePath, err)
+		return v.sorted(), nil
+	}
+	v.doc = doc
+
+	// decode config file
+	errs, err := doc.decode(v.cfg)
+	switch {
+	case err != nil:
+		v.addDecodeErrors(configFilePath, err)
+		return v.sorted(), nil
+	case len(errs) > 0:
+		v.errors = append(v.errors, errs...)
+		return v.sorted(), nil
+	}
+
+	v.validateRclone()
+	v.validateUploaders()
+	v.validateSyncers()
+	v.validateMetrics()
+	v.validateHistory()
+	v.validateAPI()
+	v.validateNotifications()
+
+	return v.sorted(), nil
+}
+
+func (e ValidationError) Error() string {
+	switch {
+	case e.Line > 0 && e.Path != "":
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	case e.Path != "":
+		return fmt.Sprintf("%s: %s", e.Path, e.Message)
+	default:
+		return e.Message
+	}
+}
+
+/* Private */
+
+func (v *validator) addDecodeErrors(file string, err error) {
+	messages := []string{err.Error()}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	for _, msg := range messages {
+		e := ValidationError{File: file, Message: msg}
+
+		// strip the file from errors of included / overlay files
+		if m := yamlFileRegex.FindStringSubmatch(msg); m != nil {
+			e.File = m[1]
+			msg = m[2]
+			e.Message = msg
+		}
+
+		if m := yamlLineRegex.FindStringSubmatch(msg); m != nil {
+			e.Line, _ = strconv.Atoi(m[1])
+			e.Message = m[2]
+		}
+
+		v.errors = append(v.errors, e)
+	}
+}
+
+func (v *validator) addError(path []interface{}, format string, args ...interface{}) {
+	file, line := v.doc.position(path)
+
+	v.errors = append(v.errors, ValidationError{
+		File:    file,
+		Line:    line,
+		Path:    formatPath(path),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) sorted() []ValidationError {
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		if v.errors[i].File != v.errors[j].File {
+			return v.errors[i].File < v.errors[j].File
+		}
+
+		return v.errors[i].Line < v.errors[j].Line
+	})
+
+	return v.errors
+}
+
+func (v *validator) validateRclone() {
+	rc := v.cfg.Rclone
+
+	// rclone binary & config
+	switch {
+	case rc.Path == "":
+		v.addError(path("rclone", "path"), "rclone binary is required")
+	default:
+		if _, err := exec.LookPath(rc.Path); err != nil {
+			v.addError(path("rclone", "path"), "rclone binary not found: %v", err)
+		}
+	}
+
+	if rc.Backend != "" && !contains(v.opts.Backends, rc.Backend) {
+		v.addError(path("rclone", "backend"), "unknown backend %q (supported: %s)", rc.Backend,
+			strings.Join(v.opts.Backends, ", "))
+	}
+
+	// rc
+	if strings.EqualFold(rc.Backend, "rcd") {
+		switch {
+		case rc.Rc.URL == "":
+			v.addError(path("rclone", "rc", "url"), "rc url is required for the rcd backend")
+		default:
+			if u, err := url.Parse(rc.Rc.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				v.addError(path("rclone", "rc", "url"), "invalid url: %q", rc.Rc.URL)
+			}
+		}
+	}
+
+	if rc.Rc.PollInterval != "" {
+		if d, err := time.ParseDuration(rc.Rc.PollInterval); err != nil || d <= 0 {
+			v.addError(path("rclone", "rc", "poll_interval"), "invalid duration: %q", rc.Rc.PollInterval)
+		}
+	}
+
+	if rc.Config != "" {
+		if _, err := os.Stat(rc.Config); err != nil {
+			v.addError(path("rclone", "config"), "rclone config not found: %v", err)
+		}
+	}
+
+	if rc.Stats != "" {
+		if _, err := time.ParseDuration(rc.Stats); err != nil {
+			v.addError(path("rclone", "stats"), "invalid duration: %q", rc.Stats)
+		}
+	}
+
+	if rc.Timeout != "" {
+		if d, err := time.ParseDuration(rc.Timeout); err != nil || d < 0 {
+			v.addError(path("rclone", "timeout"), "invalid duration: %q", rc.Timeout)
+		}
+	}
+
+	if rc.GracePeriod != "" {
+		if d, err := time.ParseDuration(rc.GracePeriod); err != nil || d < 0 {
+			v.addError(path("rclone", "grace_period"), "invalid duration: %q", rc.GracePeriod)
+		}
+	}
+
+	// service account folders
+	for folder, remotes := range rc.ServiceAccountRemotes {
+		p := path("rclone", "service_account_remotes", folder)
+
+		if _, err := ioutil.ReadDir(folder); err != nil {
+			v.addError(p, "service account folder is not readable: %v", err)
+		}
+
+		for i, remote := range remotes {
+			if remote.Remote == "" {
+				v.addError(append(p, i), "remote is required")
+			}
+
+			if remote.Strategy != "" && !contains(v.opts.Strategies, remote.Strategy) {
+				v.addError(append(p, i, "strategy"), "unknown strategy %q (supported: %s)", remote.Strategy,
+					strings.Join(v.opts.Strategies, ", "))
+			}
+		}
+	}
+
+	// quota
+	if rc.Quota.Daily != "" {
+		if _, err := humanize.ParseBytes(rc.Quota.Daily); err != nil {
+			v.addError(path("rclone", "quota", "daily"), "invalid size: %q", rc.Quota.Daily)
+		}
+	}
+
+	if rc.Quota.Reserve != "" {
+		if _, err := humanize.ParseBytes(rc.Quota.Reserve); err != nil {
+			v.addError(path("rclone", "quota", "reserve"), "invalid size: %q", rc.Quota.Reserve)
+		}
+	}
+}
+
+func (v *validator) validateCheckRules(p []interface{}, check UploaderCheck) {
+	if check.Operator != "" && !contains(v.opts.CheckOperators, check.Operator) {
+		v.addError(append(p, "operator"), "unknown check operator %q (supported: %s)", check.Operator,
+			strings.Join(v.opts.CheckOperators, ", "))
+	}
+
+	if len(check.Rules) == 0 {
+		v.addError(p, "composite checks require at least one rule")
+		return
+	}
+
+	for i, r := range check.Rules {
+		if strings.EqualFold(r.Type, "composite") || !contains(v.opts.CheckTypes, r.Type) {
+			v.addError(append(p, "rules", i, "type"), "unknown rule type %q", r.Type)
+		}
+
+		v.validateCheckDirection(append(p, "rules", i, "direction"), r.Direction)
+		v.validateCheckSchedule(append(p, "rules", i), r)
+	}
+}
+
+func (v *validator) validateCheckSchedule(p []interface{}, rule UploaderCheckRule) {
+	if !strings.EqualFold(rule.Type, "schedule") {
+		return
+	}
+
+	if len(rule.Windows) == 0 {
+		v.addError(p, "schedule checks require at least one window")
+	} else if _, err := timeutils.ParseWindows(rule.Windows); err != nil {
+		v.addError(append(p, "windows"), "%v", err)
+	}
+
+	if rule.Timezone != "" {
+		if _, err := time.LoadLocation(rule.Timezone); err != nil {
+			v.addError(append(p, "timezone"), "invalid timezone: %q", rule.Timezone)
+		}
+	}
+
+	if strings.ContainsAny(rule.BwLimit, " ,") {
+		v.addError(append(p, "bwlimit"), "invalid bwlimit %q, a single rate is expected (e.g. 1M)", rule.BwLimit)
+	}
+}
+
+func (v *validator) validateCheckDirection(p []interface{}, direction string) {
+	if direction != "" && !contains(v.opts.CheckDirections, direction) {
+		v.addError(p, "unknown check direction %q (supported: %s)", direction,
+			strings.Join(v.opts.CheckDirections, ", "))
+	}
+}
+
+func (v *validator) validateUploaders() {
+	names := make(map[string]bool)
+
+	for i, u := range v.cfg.Uploader {
+		p := path("uploader", i)
+
+		v.validateName(names, p, u.Name)
+		v.validateSchedule(append(p, "schedule"), u.Schedule)
+
+		// check
+		if !contains(v.opts.CheckTypes, u.Check.Type) {
+			v.addError(append(p, "check", "type"), "unknown check type %q (supported: %s)", u.Check.Type,
+				strings.Join(v.opts.CheckTypes, ", "))
+		}
+
+		v.validateCheckDirection(append(p, "check", "direction"), u.Check.Direction)
+		v.validateCheckSchedule(append(p, "check"), u.Check.UploaderCheckRule)
+
+		if strings.EqualFold(u.Check.Type, "composite") {
+			v.validateCheckRules(append(p, "check"), u.Check)
+		}
+
+		v.validateGlobs(append(p, "check", "include"), u.Check.Include)
+		v.validateGlobs(append(p, "check", "exclude"), u.Check.Exclude)
+
+		// in progress
+		if si := u.InProgress.SampleInterval; si != "" {
+			if d, err := time.ParseDuration(si); err != nil || d < 0 {
+				v.addError(append(p, "in_progress", "sample_interval"), "invalid duration: %q", si)
+			}
+		}
+
+		// hidden
+		if u.Hidden.Enabled {
+			if !contains(v.opts.CleanerTypes, u.Hidden.Type) {
+				v.addError(append(p, "hidden", "type"), "unknown hidden type %q (supported: %s)", u.Hidden.Type,
+					strings.Join(v.opts.CleanerTypes, ", "))
+			}
+
+			if u.Hidden.Folder == "" {
+				v.addError(append(p, "hidden", "folder"), "hidden folder is required when hidden is enabled")
+			}
+		}
+
+		// local folder
+		if u.LocalFolder == "" {
+			v.addError(append(p, "local_folder"), "local folder is required")
+		} else if _, err := os.Stat(u.LocalFolder); err != nil {
+			v.addError(append(p, "local_folder"), "local folder not found: %v", err)
+		}
+
+		// remotes
+		if u.Remotes.Move == "" && len(u.Remotes.Copy) == 0 {
+			v.addError(append(p, "remotes"), "a move or copy remote is required")
+		}
+
+		v.validateServerSide(append(p, "remotes", "move_server_side"), u.Remotes.MoveServerSide)
+
+		// global params
+		v.validateGlobalParams(append(p, "rclone_params"), map[string]string{
+			"global_copy":             u.RcloneParams.GlobalCopy,
+			"global_move":             u.RcloneParams.GlobalMove,
+			"global_move_server_side": u.RcloneParams.GlobalMoveServerSide,
+			"global_dedupe":           u.RcloneParams.GlobalDedupe,
+		})
+	}
+}
+
+func (v *validator) validateSyncers() {
+	names := make(map[string]bool)
+
+	for i, s := range v.cfg.Syncer {
+		p := path("syncer", i)
+
+		v.validateName(names, p, s.Name)
+		v.validateSchedule(append(p, "schedule"), s.Schedule)
+
+		if s.SourceRemote == "" {
+			v.addError(append(p, "source_remote"), "source remote is required")
+		}
+
+		// remotes
+		if len(s.Remotes.Copy) == 0 && len(s.Remotes.Sync) == 0 && len(s.Remotes.MoveServerSide) == 0 {
+			v.addError(append(p, "remotes"), "a copy, sync or move_server_side remote is required")
+		}
+
+		v.validateServerSide(append(p, "remotes", "move_server_side"), s.Remotes.MoveServerSide)
+
+		// global params
+		v.validateGlobalParams(append(p, "rclone_params"), map[string]string{
+			"global_copy":             s.RcloneParams.GlobalCopy,
+			"global_sync":             s.RcloneParams.GlobalSync,
+			"global_move_server_side": s.RcloneParams.GlobalMoveServerSide,
+			"global_dedupe":           s.RcloneParams.GlobalDedupe,
+		})
+	}
+}
+
+func (v *validator) validateMetrics() {
+	m := v.cfg.Metrics
+
+	if m.Listen == "" {
+		return
+	}
+
+	if _, port, err := net.SplitHostPort(m.Listen); err != nil || port == "" {
+		v.addError(path("metrics", "listen"), "invalid address, expected host:port: %q", m.Listen)
+	}
+}
+
+func (v *validator) validateHistory() {
+	h := v.cfg.History
+
+	if h.Retention == "" {
+		return
+	}
+
+	if d, err := time.ParseDuration(h.Retention); err != nil || d < 0 {
+		v.addError(path("history", "retention"), "invalid duration: %q", h.Retention)
+	}
+}
+
+func (v *validator) validateAPI() {
+	a := v.cfg.API
+
+	if a.Listen == "" {
+		return
+	}
+
+	if _, port, err := net.SplitHostPort(a.Listen); err != nil || port == "" {
+		v.addError(path("api", "listen"), "invalid address, expected host:port: %q", a.Listen)
+	}
+
+	if a.Token == "" {
+		v.addError(path("api", "token"), "token is required")
+	}
+}
+
+func (v *validator) validateNotifications() {
+	names := make(map[string]bool)
+
+	for i, n := range v.cfg.Notifications {
+		p := path("notifications", i)
+
+		v.validateName(names, p, n.Name)
+
+		if !contains(v.opts.NotificationTypes, n.Type) {
+			v.addError(append(p, "type"), "unknown notification type %q (supported: %s)", n.Type,
+				strings.Join(v.opts.NotificationTypes, ", "))
+		}
+
+		for j, e := range n.Events {
+			if !contains(v.opts.NotificationEvents, e) {
+				v.addError(append(p, "events", j), "unknown event %q (supported: %s)", e,
+					strings.Join(v.opts.NotificationEvents, ", "))
+			}
+		}
+
+		// provider settings
+		switch {
+		case strings.EqualFold(n.Type, "smtp"):
+			if n.Host == "" {
+				v.addError(append(p, "host"), "host is required")
+			}
+
+			if n.From == "" {
+				v.addError(append(p, "from"), "from is required")
+			}
+
+			if len(n.To) == 0 {
+				v.addError(append(p, "to"), "at least one recipient is required")
+			}
+		case n.URL == "":
+			v.addError(append(p, "url"), "url is required")
+		default:
+			if u, err := url.Parse(n.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				v.addError(append(p, "url"), "invalid url: %q", n.URL)
+			}
+		}
+	}
+}
+
+func (v *validator) validateName(names map[string]bool, p []interface{}, name string) {
+	switch {
+	case name == "":
+		v.addError(append(p, "name"), "name is required")
+	case names[strings.ToLower(name)]:
+		v.addError(append(p, "name"), "duplicate name %q", name)
+	default:
+		names[strings.ToLower(name)] = true
+	}
+}
+
+func (v *validator) validateSchedule(p []interface{}, schedule string) {
+	if schedule == "" {
+		return
+	}
+
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		v.addError(p, "invalid schedule %q: %v", schedule, err)
+	}
+}
+
+func (v *validator) validateGlobs(p []interface{}, globs []string) {
+	for i, glob := range globs {
+		if _, err := reutils.GlobToRegexp(glob, false); err != nil {
+			v.addError(append(p, i), "invalid glob: %v", err)
+		}
+	}
+}
+
+func (v *validator) validateServerSide(p []interface{}, remotes []RcloneServerSide) {
+	for i, r := range remotes {
+		if r.From == "" || r.To == "" {
+			v.addError(append(p, i), "from and to are required")
+		}
+	}
+}
+
+func (v *validator) validateGlobalParams(p []interface{}, refs map[string]string) {
+	for key, name := range refs {
+		if name == "" {
+			continue
+		}
+
+		if _, ok := v.cfg.Rclone.GlobalParams[name]; !ok {
+			v.addError(append(p, key), "global params %q not found in rclone.global_params", name)
+		}
+	}
+}
+
+func path(keys ...interface{}) []interface{} {
+	return keys
+}
+
+func formatPath(p []interface{}) string {
+	var sb strings.Builder
+
+	for _, key := range p {
+		switch k := key.(type) {
+		case int:
+			sb.WriteString(fmt.Sprintf("[%d]", k))
+		default:
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(fmt.Sprint(k))
+		}
+	}
+
+	return sb.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
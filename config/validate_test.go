@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	saFolder := t.TempDir()
+
+	opts := ValidateOptions{
+		CheckTypes: []string{"age", "size"},
+		Strategies: []string{"sequential", "round_robin"},
+	}
+
+	tests := []struct {
+		name     string
+		config   string
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:     "unknown key",
+			config:   "rclone:\n  path: true\nuploaders:\n  - name: tv\n",
+			wantLine: 3,
+			wantErr:  "field uploaders not found",
+		},
+		{
+			name:     "unknown nested key",
+			config:   "rclone:\n  path: true\n  dryrun: true\n",
+			wantLine: 3,
+			wantErr:  "field dryrun not found",
+		},
+		{
+			name:     "invalid type",
+			config:   "rclone:\n  path: true\n  dry_run: maybe\n",
+			wantLine: 3,
+			wantErr:  "cannot unmarshal",
+		},
+		{
+			name: "unknown check type",
+			config: `rclone:
+  path: true
+uploader:
+  - name: tv
+    check:
+      type: sizes
+    local_folder: /mnt/tv
+`,
+			wantLine: 6,
+			wantErr:  `unknown check type "sizes"`,
+		},
+		{
+			name: "unknown strategy",
+			config: fmt.Sprintf(`rclone:
+  path: true
+  service_account_remotes:
+    %s:
+      - remote: tv
+        strategy: fastest
+`, saFolder),
+			wantLine: 6,
+			wantErr:  `unknown strategy "fastest"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			errs, err := Validate(path, opts)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			for _, e := range errs {
+				if e.Line == tt.wantLine && strings.Contains(e.Message, tt.wantErr) {
+					return
+				}
+			}
+
+			t.Errorf("Validate() = %v, want line %d: %s", errs, tt.wantLine, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := fmt.Sprintf(`rclone:
+  path: true
+uploader:
+  - name: tv
+    check:
+      type: age
+      limit: 60
+    local_folder: %s
+    remotes:
+      move: gdrive:/Media/TV
+`, t.TempDir())
+
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, err := Validate(path, ValidateOptions{CheckTypes: []string{"age"}})
+	if err != nil || len(errs) > 0 {
+		t.Errorf("Validate() = %v, %v, want no errors", errs, err)
+	}
+}
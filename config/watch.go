@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// editors often write a file in several steps, wait for them to settle
+	watchDebounce = 2 * time.Second
+)
+
+/* Struct */
+
+type watcher struct {
+	w           *fsnotify.Watcher
+	configPath  string
+	overlayPath string
+
+	// files the config was loaded from, and the folders watched for them
+	files   map[string]bool
+	folders map[string]bool
+}
+
+/* Public */
+
+// Watch calls fn when the config file, an overlay within config.d or a file included / referenced by them changes,
+// until stop is closed.
+func Watch(configFilePath string, stop <-chan struct{}, fn func()) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed creating watcher: %w", err)
+	}
+
+	configPath := filepath.Clean(configFilePath)
+	w := &watcher{
+		w:           fw,
+		configPath:  configPath,
+		overlayPath: filepath.Join(filepath.Dir(configPath), overlayFolder),
+		files:       map[string]bool{configPath: true},
+		folders:     make(map[string]bool),
+	}
+
+	// watch folders rather than files, so files replaced by a rename are still seen
+	if err := fw.Add(filepath.Dir(configPath)); err != nil {
+		_ = fw.Close()
+		return fmt.Errorf("failed watching %q: %w", filepath.Dir(configPath), err)
+	}
+	w.folders[filepath.Dir(configPath)] = true
+
+	w.refresh()
+
+	go func() {
+		defer fw.Close()
+
+		var debounce <-chan time.Time
+
+		for {
+			select {
+			case <-stop:
+				return
+			case e, ok := <-fw.Events:
+				if !ok {
+					return
+				}
+
+				if !w.watched(e.Name) {
+					continue
+				}
+
+				log.Tracef("Config change detected: %v", e)
+				debounce = time.After(watchDebounce)
+			case err, ok := <-fw.Errors:
+				if !ok {
+					return
+				}
+
+				log.WithError(err).Error("Config watcher error")
+			case <-debounce:
+				debounce = nil
+
+				// includes may have been added or removed
+				w.refresh()
+				fn()
+			}
+		}
+	}()
+
+	return nil
+}
+
+/* Private */
+
+// refresh watches the folders of the files the config currently loads from.
+func (w *watcher) refresh() {
+	doc := newDocument(w.configPath)
+	if err := doc.load(); err != nil {
+		log.WithError(err).Trace("Config did not load, watching the files read so far")
+	}
+
+	files := map[string]bool{w.configPath: true}
+	for f := range doc.sources {
+		files[f] = true
+	}
+
+	// the config folder is watched for config.d being created
+	folders := map[string]bool{filepath.Dir(w.configPath): true, w.overlayPath: true}
+	for f := range files {
+		folders[filepath.Dir(f)] = true
+	}
+
+	for f := range folders {
+		if w.folders[f] {
+			continue
+		}
+
+		if err := w.w.Add(f); err != nil {
+			log.WithError(err).Tracef("Not watching folder: %q", f)
+			continue
+		}
+
+		w.folders[f] = true
+	}
+
+	for f := range w.folders {
+		if !folders[f] {
+			_ = w.w.Remove(f)
+			delete(w.folders, f)
+		}
+	}
+
+	w.files = files
+}
+
+func (w *watcher) watched(name string) bool {
+	name = filepath.Clean(name)
+
+	switch {
+	case w.files[name]:
+		return true
+	case name == w.overlayPath:
+		// config.d was created or removed
+		delete(w.folders, name)
+		return true
+	case filepath.Dir(name) == w.overlayPath:
+		ext := strings.ToLower(filepath.Ext(name))
+		return ext == ".yaml" || ext == ".yml"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	debounce := watchDebounce
+	watchDebounce = 100 * time.Millisecond
+	t.Cleanup(func() {
+		watchDebounce = debounce
+	})
+
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "rclone: !include rclone.yaml\n")
+	writeFile(t, dir, "rclone.yaml", "dry_run: true\n")
+
+	calls := make(chan struct{}, 10)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	if err := Watch(filepath.Join(dir, "config.yaml"), stop, func() {
+		calls <- struct{}{}
+	}); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	steps := []struct {
+		name   string
+		change func()
+		want   int
+	}{
+		{"writes are debounced", func() {
+			for i := 0; i < 3; i++ {
+				writeFile(t, dir, "config.yaml", "rclone: !include rclone.yaml\n")
+				time.Sleep(10 * time.Millisecond)
+			}
+		}, 1},
+		{"unrelated file", func() {
+			writeFile(t, dir, "notes.txt", "not config")
+		}, 0},
+		{"included file", func() {
+			writeFile(t, dir, "rclone.yaml", "dry_run: false\n")
+		}, 1},
+		{"overlay folder created", func() {
+			writeFile(t, dir, "config.d/10-tv.yaml", "uploader: []\n")
+		}, 1},
+		{"overlay changed", func() {
+			writeFile(t, dir, "config.d/10-tv.yaml", "syncer: []\n")
+		}, 1},
+		{"non yaml overlay", func() {
+			writeFile(t, dir, "config.d/notes.txt", "not config")
+		}, 0},
+	}
+
+	for _, s := range steps {
+		s.change()
+
+		got := 0
+		timeout := time.After(5 * watchDebounce)
+
+	wait:
+		for {
+			select {
+			case <-calls:
+				got++
+			case <-timeout:
+				break wait
+			}
+		}
+
+		if got != s.want {
+			t.Errorf("%s: called %d time(s), want %d", s.name, got, s.want)
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
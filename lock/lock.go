@@ -0,0 +1,198 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/l3uddz/crop/logger"
+	"github.com/nightlyone/lockfile"
+	"github.com/shirou/gopsutil/process"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	log = logger.GetLogger("lock")
+
+	// ErrTimeout is returned when a lock could not be acquired within the timeout
+	ErrTimeout = errors.New("timed out waiting for lock")
+)
+
+const (
+	retryInterval = 1 * time.Minute
+)
+
+/* Struct */
+
+type Lock struct {
+	Path string
+	lf   lockfile.Lockfile
+}
+
+type Owner struct {
+	Pid     int
+	Started time.Time
+}
+
+type BusyError struct {
+	Path  string
+	Owner *Owner
+}
+
+/* Public */
+
+func New(path string) (*Lock, error) {
+	p, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed determining absolute lock path for %q: %w", path, err)
+	}
+
+	lf, err := lockfile.New(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lock{
+		Path: p,
+		lf:   lf,
+	}, nil
+}
+
+func (l *Lock) TryLock() error {
+	err := l.lf.TryLock()
+	if err != lockfile.ErrBusy {
+		// the lock was acquired or an un-expected error occurred
+		return err
+	}
+
+	// the lock is held by a running process, determine who
+	owner, oerr := l.Owner()
+	if oerr != nil {
+		// the owner has likely released the lock in the meantime
+		log.WithError(oerr).Tracef("Failed determining owner of lock: %q", l.Path)
+		return &BusyError{Path: l.Path}
+	}
+
+	// is this lock stale?
+	if !l.stale(owner) {
+		return &BusyError{Path: l.Path, Owner: owner}
+	}
+
+	log.WithFields(logrus.Fields{
+		"lock":        l.Path,
+		"pid":         owner.Pid,
+		"pid_started": owner.Started,
+	}).Warn("Removing stale lock as its pid now belongs to a different process")
+
+	if err := os.Remove(l.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed removing stale lock %q: %w", l.Path, err)
+	}
+
+	return l.lf.TryLock()
+}
+
+// Lock waits until the lock has been acquired, timeout has passed (when set) or ctx is cancelled.
+func (l *Lock) Lock(ctx context.Context, timeout time.Duration) error {
+	started := time.Now()
+
+	// loop until lock has been acquired
+	for {
+		err := l.TryLock()
+
+		var busy *BusyError
+		if !errors.As(err, &busy) {
+			// lock has been acquired or an un-expected error occurred
+			return err
+		}
+
+		// another instance is holding the lock
+		wait := retryInterval
+		if timeout > 0 {
+			remaining := timeout - time.Since(started)
+			if remaining <= 0 {
+				return fmt.Errorf("%w: %v", ErrTimeout, busy)
+			}
+
+			if remaining < wait {
+				wait = remaining
+			}
+		}
+
+		fields := logrus.Fields{
+			"lock": l.Path,
+		}
+
+		if busy.Owner != nil {
+			fields["pid"] = busy.Owner.Pid
+			fields["pid_started"] = busy.Owner.Started
+		}
+
+		log.WithFields(fields).Warnf("There is another crop instance holding the lock, re-checking in %v...",
+			wait.Round(time.Millisecond))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (l *Lock) Unlock() error {
+	return l.lf.Unlock()
+}
+
+func (l *Lock) Owner() (*Owner, error) {
+	p, err := l.lf.GetOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	owner := &Owner{
+		Pid: p.Pid,
+	}
+
+	// determine when the owner was started
+	proc, err := process.NewProcess(int32(p.Pid))
+	if err != nil {
+		return owner, nil
+	}
+
+	if ms, err := proc.CreateTime(); err == nil {
+		owner.Started = time.Unix(0, ms*int64(time.Millisecond))
+	}
+
+	return owner, nil
+}
+
+func (e *BusyError) Error() string {
+	if e.Owner == nil {
+		return fmt.Sprintf("lock %q is held by another process", e.Path)
+	}
+
+	if e.Owner.Started.IsZero() {
+		return fmt.Sprintf("lock %q is held by pid %d", e.Path, e.Owner.Pid)
+	}
+
+	return fmt.Sprintf("lock %q is held by pid %d (started %s)", e.Path, e.Owner.Pid,
+		e.Owner.Started.Format(time.RFC3339))
+}
+
+/* Private */
+
+func (l *Lock) stale(owner *Owner) bool {
+	if owner.Started.IsZero() {
+		// we cannot tell when the owner was started
+		return false
+	}
+
+	fi, err := os.Stat(l.Path)
+	if err != nil {
+		return false
+	}
+
+	// a process started after the lock was written cannot be the owner (the pid was re-used)
+	return owner.Started.After(fi.ModTime().Add(time.Second))
+}
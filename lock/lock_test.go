@@ -0,0 +1,124 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		written time.Time
+		wantErr bool
+	}{
+		{"held by a running process", time.Now(), true},
+		{"stale, the pid was re-used after the lock was written", time.Now().Add(-time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newHeldLock(t, tt.written)
+
+			err := l.TryLock()
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("TryLock() error = %v", err)
+				}
+
+				if owner, err := l.Owner(); err != nil || owner.Pid != os.Getpid() {
+					t.Errorf("Owner() = %v, %v, want pid %d", owner, err, os.Getpid())
+				}
+				return
+			}
+
+			var busy *BusyError
+			if !errors.As(err, &busy) {
+				t.Fatalf("TryLock() error = %v, want a BusyError", err)
+			}
+
+			if busy.Owner == nil || busy.Owner.Started.IsZero() {
+				t.Errorf("BusyError.Owner = %v, want the holder's pid & start time", busy.Owner)
+			}
+		})
+	}
+}
+
+func TestLock(t *testing.T) {
+	t.Run("free", func(t *testing.T) {
+		l, err := New(filepath.Join(t.TempDir(), "crop.lock"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := l.Lock(context.Background(), time.Second); err != nil {
+			t.Fatalf("Lock() error = %v", err)
+		}
+
+		if err := l.Unlock(); err != nil {
+			t.Errorf("Unlock() error = %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		l := newHeldLock(t, time.Now())
+
+		started := time.Now()
+		err := l.Lock(context.Background(), 50*time.Millisecond)
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("Lock() error = %v, want %v", err, ErrTimeout)
+		}
+
+		if elapsed := time.Since(started); elapsed > 5*time.Second {
+			t.Errorf("Lock() took %v, want about the timeout", elapsed)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		l := newHeldLock(t, time.Now())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// without a timeout, only the cancellation stops waiting
+		if err := l.Lock(ctx, 0); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Lock() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+// newHeldLock returns a lock held by another running process, written at the given time.
+func newHeldLock(t *testing.T, written time.Time) *Lock {
+	t.Helper()
+
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Failed starting a process to hold the lock: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	l, err := New(filepath.Join(t.TempDir(), "crop.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(l.Path, []byte(fmt.Sprintf("%d\n", cmd.Process.Pid)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(l.Path, written, written); err != nil {
+		t.Fatal(err)
+	}
+
+	return l
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"github.com/l3uddz/crop/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* Struct */
+
+// oldestFileCollector reports the age of the oldest local file at scrape time, rather than when it was found.
+type oldestFileCollector struct {
+	desc *prometheus.Desc
+
+	mtx    sync.Mutex
+	oldest map[string]time.Time
+}
+
+// banCollector reports the bans stored in the cache at scrape time.
+type banCollector struct{}
+
+/* Var */
+
+var (
+	oldestFiles = &oldestFileCollector{
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "local_oldest_file_age_seconds"),
+			"Age of the oldest file found in the uploader's local folder.", []string{"uploader"}, nil),
+		oldest: make(map[string]time.Time),
+	}
+
+	bansDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "bans"),
+		"Banned remotes & service accounts.", []string{"type"}, nil)
+)
+
+/* Public */
+
+func (c *oldestFileCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *oldestFileCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for uploader, t := range c.oldest {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, time.Since(t).Seconds(), uploader)
+	}
+}
+
+func (c *banCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bansDesc
+}
+
+func (c *banCollector) Collect(ch chan<- prometheus.Metric) {
+	bans, err := cache.GetBans()
+	if err != nil {
+		log.WithError(err).Error("Failed retrieving bans")
+		return
+	}
+
+	remotes, serviceAccounts := 0, 0
+	for _, b := range bans {
+		// service accounts are banned by their file path, remotes by their name
+		if strings.HasSuffix(strings.ToLower(b.Path), ".json") {
+			serviceAccounts++
+		} else {
+			remotes++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(bansDesc, prometheus.GaugeValue, float64(remotes), "remote")
+	ch <- prometheus.MustNewConstMetric(bansDesc, prometheus.GaugeValue, float64(serviceAccounts), "service_account")
+}
+
+/* Private */
+
+func (c *oldestFileCollector) set(uploader string, oldest time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if oldest.IsZero() {
+		// there were no files
+		delete(c.oldest, uploader)
+		return
+	}
+
+	c.oldest[uploader] = oldest
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/l3uddz/crop/logger"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* Const */
+
+const (
+	namespace       = "crop"
+	shutdownTimeout = 5 * time.Second
+
+	StatusSuccess   = "success"
+	StatusFailure   = "failure"
+	StatusCancelled = "cancelled"
+)
+
+/* Var */
+
+var (
+	log = logger.GetLogger("metrics")
+
+	runsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_total",
+		Help:      "Uploader & syncer runs by status.",
+	}, []string{"scope", "name", "status"})
+
+	runDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "run_duration_seconds",
+		Help:      "Duration of uploader & syncer runs.",
+		Buckets:   prometheus.ExponentialBuckets(30, 2, 10),
+	}, []string{"scope", "name"})
+
+	transferredBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transferred_bytes_total",
+		Help:      "Bytes transferred by uploader & syncer runs.",
+	}, []string{"scope", "name"})
+
+	rcloneExits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rclone_exits_total",
+		Help:      "rclone operations by command & exit code.",
+	}, []string{"command", "exit_code"})
+
+	serviceAccountRotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "service_account_rotations_total",
+		Help:      "Service accounts handed out to rclone by the service account server.",
+	}, []string{"remote"})
+
+	localFiles = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "local_files",
+		Help:      "Files found in the uploader's local folder.",
+	}, []string{"uploader"})
+
+	localFilesSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "local_files_bytes",
+		Help:      "Size of the files found in the uploader's local folder.",
+	}, []string{"uploader"})
+
+	registry     = prometheus.NewRegistry()
+	observerOnce sync.Once
+)
+
+/* Private */
+
+func init() {
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		runsTotal,
+		runDuration,
+		transferredBytes,
+		rcloneExits,
+		serviceAccountRotations,
+		localFiles,
+		localFilesSize,
+		oldestFiles,
+		&banCollector{},
+	)
+}
+
+/* Public */
+
+// Serve exposes /metrics on listen until ctx is cancelled.
+func Serve(ctx context.Context, listen string) error {
+	// record rclone exit codes
+	observerOnce.Do(func() {
+		rclone.AddObserver(func(op *rclone.Operation, e *rclone.Event) {
+			if e.Type == rclone.EventExit {
+				rcloneExits.WithLabelValues(op.Command, strconv.Itoa(e.ExitCode)).Inc()
+			}
+		})
+	})
+
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		log.Infof("Serving metrics on: http://%s/metrics", l.Addr())
+
+		if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("Metrics server failed...")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(sctx); err != nil {
+			log.WithError(err).Error("Failed shutting down metrics server...")
+		}
+	}()
+
+	return nil
+}
+
+// ObserveRun records a finished uploader / syncer run.
+func ObserveRun(scope string, name string, elapsed time.Duration, stats rclone.Stats, err error) {
+	status := RunStatus(err)
+
+	runsTotal.WithLabelValues(scope, name, status).Inc()
+	runDuration.WithLabelValues(scope, name).Observe(elapsed.Seconds())
+	transferredBytes.WithLabelValues(scope, name).Add(float64(stats.Bytes))
+}
+
+// ObserveLocalFiles records the files found in an uploader's local folder.
+func ObserveLocalFiles(uploader string, count int, size uint64, oldest time.Time) {
+	localFiles.WithLabelValues(uploader).Set(float64(count))
+	localFilesSize.WithLabelValues(uploader).Set(float64(size))
+	oldestFiles.set(uploader, oldest)
+}
+
+// RunStatus is the status of a run that finished with err.
+func RunStatus(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return StatusCancelled
+	case err != nil:
+		return StatusFailure
+	default:
+		return StatusSuccess
+	}
+}
+
+// ServiceAccountRotated records a service account handed out to rclone for remote.
+func ServiceAccountRotated(remote string) {
+	serviceAccountRotations.WithLabelValues(remote).Inc()
+}
@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/logger"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* Const */
+
+const (
+	EventRunStarted               Event = "run_started"
+	EventRunFinished              Event = "run_finished"
+	EventRunFailed                Event = "run_failed"
+	EventServiceAccountsExhausted Event = "sa_exhausted"
+	EventRemoteBanned             Event = "remote_banned"
+	EventFreeSpaceForcedUpload    Event = "free_space_forced_upload"
+
+	sendTimeout = 10 * time.Second
+)
+
+/* Interface */
+
+type Provider interface {
+	Send(ctx context.Context, n *Notification) error
+}
+
+/* Struct */
+
+type Event string
+
+type Notification struct {
+	Event   Event
+	Scope   string
+	Name    string
+	Message string
+	Fields  map[string]interface{}
+	Time    time.Time
+	Host    string
+}
+
+type notifier struct {
+	name     string
+	events   map[Event]bool
+	provider Provider
+}
+
+/* Var */
+
+var (
+	log = logger.GetLogger("notify")
+
+	hostname, _ = os.Hostname()
+
+	notifiers []*notifier
+	mtx       sync.RWMutex
+
+	providers = map[string]func(cfg config.NotificationConfig) (Provider, error){
+		"webhook": newWebhook,
+		"discord": newDiscord,
+		"slack":   newSlack,
+		"ntfy":    newNtfy,
+		"gotify":  newGotify,
+		"smtp":    newSMTP,
+	}
+)
+
+/* Public */
+
+func SupportedTypes() []string {
+	types := make([]string, 0, len(providers))
+	for t := range providers {
+		types = append(types, t)
+	}
+
+	sort.Strings(types)
+	return types
+}
+
+func SupportedEvents() []string {
+	return []string{
+		string(EventRunStarted),
+		string(EventRunFinished),
+		string(EventRunFailed),
+		string(EventServiceAccountsExhausted),
+		string(EventRemoteBanned),
+		string(EventFreeSpaceForcedUpload),
+	}
+}
+
+func Init(cfgs []config.NotificationConfig) error {
+	ns := make([]*notifier, 0, len(cfgs))
+
+	for _, cfg := range cfgs {
+		fn, ok := providers[strings.ToLower(cfg.Type)]
+		if !ok {
+			return fmt.Errorf("unknown notification type for %q: %q", cfg.Name, cfg.Type)
+		}
+
+		p, err := fn(cfg)
+		if err != nil {
+			return fmt.Errorf("failed initializing notification %q: %w", cfg.Name, err)
+		}
+
+		n := &notifier{
+			name:     cfg.Name,
+			events:   make(map[Event]bool),
+			provider: p,
+		}
+
+		for _, e := range cfg.Events {
+			n.events[Event(strings.ToLower(e))] = true
+		}
+
+		ns = append(ns, n)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	notifiers = ns
+	return nil
+}
+
+// Send delivers n to every notification subscribed to its event, failures are only logged.
+func Send(n *Notification) {
+	// a reload must not wait on sends in progress
+	mtx.RLock()
+	ns := notifiers
+	mtx.RUnlock()
+
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	if n.Host == "" {
+		n.Host = hostname
+	}
+
+	// send concurrently, so a slow notification delays the caller by at most sendTimeout
+	var wg sync.WaitGroup
+	for _, nt := range ns {
+		// no events means all events
+		if len(nt.events) > 0 && !nt.events[n.Event] {
+			continue
+		}
+
+		wg.Add(1)
+		go func(nt *notifier) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+
+			if err := nt.provider.Send(ctx, n); err != nil {
+				log.WithError(err).Errorf("Failed sending %s notification via: %s", n.Event, nt.name)
+				return
+			}
+
+			log.Debugf("Sent %s notification via: %s", n.Event, nt.name)
+		}(nt)
+	}
+
+	wg.Wait()
+}
+
+func (n *Notification) Title() string {
+	if n.Name == "" {
+		return fmt.Sprintf("crop (%s): %s", n.Host, n.Event)
+	}
+
+	return fmt.Sprintf("crop (%s): %s %s %s", n.Host, n.Scope, n.Name, n.Event)
+}
+
+// Text is the message followed by its fields, one per line.
+func (n *Notification) Text() string {
+	lines := []string{n.Message}
+
+	keys := make([]string, 0, len(n.Fields))
+	for k := range n.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %v", k, n.Fields[k]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+/* Private */
+
+func (n *Notification) failure() bool {
+	switch n.Event {
+	case EventRunFailed, EventServiceAccountsExhausted, EventRemoteBanned:
+		return true
+	default:
+		return false
+	}
+}
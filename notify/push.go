@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/l3uddz/crop/config"
+	"strings"
+)
+
+/* Struct */
+
+type ntfy struct {
+	url   string
+	token string
+}
+
+type gotify struct {
+	url   string
+	token string
+}
+
+/* Public */
+
+func (p *ntfy) Send(ctx context.Context, n *Notification) error {
+	headers := map[string]string{
+		"Title": n.Title(),
+		"Tags":  string(n.Event),
+	}
+
+	if n.failure() {
+		headers["Priority"] = "high"
+	}
+
+	if p.token != "" {
+		headers["Authorization"] = "Bearer " + p.token
+	}
+
+	return post(ctx, p.url, headers, "text/plain", strings.NewReader(n.Text()))
+}
+
+func (p *gotify) Send(ctx context.Context, n *Notification) error {
+	priority := 5
+	if n.failure() {
+		priority = 8
+	}
+
+	b, err := json.Marshal(map[string]interface{}{
+		"title":    n.Title(),
+		"message":  n.Text(),
+		"priority": priority,
+	})
+	if err != nil {
+		return fmt.Errorf("failed encoding payload: %w", err)
+	}
+
+	return post(ctx, p.url+"/message", map[string]string{"X-Gotify-Key": p.token}, "application/json",
+		bytes.NewReader(b))
+}
+
+/* Private */
+
+func newNtfy(cfg config.NotificationConfig) (Provider, error) {
+	// the url includes the topic, e.g. https://ntfy.sh/crop
+	return &ntfy{
+		url:   cfg.URL,
+		token: cfg.Token,
+	}, nil
+}
+
+func newGotify(cfg config.NotificationConfig) (Provider, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("an application token is required")
+	}
+
+	return &gotify{
+		url:   strings.TrimSuffix(cfg.URL, "/"),
+		token: cfg.Token,
+	}, nil
+}
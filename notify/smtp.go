@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/l3uddz/crop/config"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* Const */
+
+const (
+	defaultSMTPPort = 587
+)
+
+/* Struct */
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+/* Public */
+
+func (m *smtpMailer) Send(ctx context.Context, n *Notification) error {
+	msg := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + strings.Join(m.to, ", "),
+		"Subject: " + n.Title(),
+		"Date: " + n.Time.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		strings.ReplaceAll(n.Text(), "\n", "\r\n"),
+	}, "\r\n")
+
+	// net/smtp is not context aware, run it in the background so a slow server does not block
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- smtp.SendMail(m.addr, m.auth, m.from, m.to, []byte(msg))
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("failed sending mail: %w", ctx.Err())
+	}
+}
+
+/* Private */
+
+func newSMTP(cfg config.NotificationConfig) (Provider, error) {
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+
+	m := &smtpMailer{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		from: cfg.From,
+		to:   cfg.To,
+	}
+
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return m, nil
+}
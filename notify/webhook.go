@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/l3uddz/crop/config"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+/* Struct */
+
+// webhook posts a json document built by payload to url.
+type webhook struct {
+	url     string
+	headers map[string]string
+	payload func(n *Notification) interface{}
+}
+
+type webhookPayload struct {
+	Event   Event                  `json:"event"`
+	Scope   string                 `json:"scope,omitempty"`
+	Name    string                 `json:"name,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Time    time.Time              `json:"time"`
+	Host    string                 `json:"host"`
+}
+
+/* Public */
+
+func (w *webhook) Send(ctx context.Context, n *Notification) error {
+	b, err := json.Marshal(w.payload(n))
+	if err != nil {
+		return fmt.Errorf("failed encoding payload: %w", err)
+	}
+
+	return post(ctx, w.url, w.headers, "application/json", bytes.NewReader(b))
+}
+
+/* Private */
+
+func newWebhook(cfg config.NotificationConfig) (Provider, error) {
+	w := &webhook{
+		url:     cfg.URL,
+		headers: make(map[string]string),
+		payload: func(n *Notification) interface{} {
+			return &webhookPayload{
+				Event:   n.Event,
+				Scope:   n.Scope,
+				Name:    n.Name,
+				Message: n.Message,
+				Fields:  n.Fields,
+				Time:    n.Time.UTC(),
+				Host:    n.Host,
+			}
+		},
+	}
+
+	if cfg.Token != "" {
+		w.headers["Authorization"] = "Bearer " + cfg.Token
+	}
+
+	return w, nil
+}
+
+func newDiscord(cfg config.NotificationConfig) (Provider, error) {
+	return &webhook{
+		url: cfg.URL,
+		payload: func(n *Notification) interface{} {
+			return map[string]interface{}{
+				"username": "crop",
+				"content":  fmt.Sprintf("**%s**\n%s", n.Title(), n.Text()),
+			}
+		},
+	}, nil
+}
+
+func newSlack(cfg config.NotificationConfig) (Provider, error) {
+	return &webhook{
+		url: cfg.URL,
+		payload: func(n *Notification) interface{} {
+			return map[string]interface{}{
+				"text": fmt.Sprintf("*%s*\n%s", n.Title(), n.Text()),
+			}
+		},
+	}, nil
+}
+
+func post(ctx context.Context, url string, headers map[string]string, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return fmt.Errorf("failed creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected response: %s: %s", res.Status, strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package pathutils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/* Public */
+
+// GetFilesOpenForWriting returns the files within folder (relative to it) that a process has open for writing, by
+// scanning the file descriptors in /proc. Processes that cannot be inspected (e.g. of other users) are ignored, and
+// nothing is returned where /proc is not available.
+func GetFilesOpenForWriting(folder string) map[string]bool {
+	files := make(map[string]bool)
+
+	// the links in /proc are resolved
+	root, err := filepath.EvalSymlinks(folder)
+	if err != nil {
+		root = folder
+	}
+	root = strings.TrimSuffix(root, "/") + "/"
+
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return files
+	}
+
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil {
+			// not a process
+			continue
+		}
+
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, root) {
+				continue
+			}
+
+			if fdOpenForWriting(filepath.Join("/proc", proc.Name(), "fdinfo", fd.Name())) {
+				files[strings.TrimPrefix(target, root)] = true
+			}
+		}
+	}
+
+	return files
+}
+
+/* Private */
+
+func fdOpenForWriting(fdInfoPath string) bool {
+	f, err := os.Open(fdInfoPath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		value := strings.TrimPrefix(s.Text(), "flags:")
+		if value == s.Text() {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(strings.TrimSpace(value), 8, 64)
+		if err != nil {
+			return false
+		}
+
+		// O_WRONLY or O_RDWR
+		return flags&uint64(os.O_WRONLY|os.O_RDWR) != 0
+	}
+
+	return false
+}
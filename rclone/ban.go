@@ -0,0 +1,181 @@
+package rclone
+
+import (
+	"fmt"
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/notify"
+	"github.com/sirupsen/logrus"
+	"path/filepath"
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+const (
+	defaultBanHours    = 25
+	defaultBanMaxHours = 168
+
+	// strikes are forgotten after a week without another ban
+	strikeResetAfter = 7 * 24 * time.Hour
+)
+
+var (
+	// google drive quotas reset at midnight pacific time
+	quotaResetLocation = loadQuotaResetLocation()
+)
+
+/* Public */
+
+func BanRemote(remote string) error {
+	expires, err := ban(remote, remoteBanHours(remote))
+	if err != nil {
+		return err
+	}
+
+	notify.Send(&notify.Notification{
+		Event:   notify.EventRemoteBanned,
+		Message: fmt.Sprintf("Banned remote %q", remote),
+		Fields: map[string]interface{}{
+			"remote":       remote,
+			"expires_time": expires.UTC().Format(time.RFC3339),
+		},
+	})
+
+	return nil
+}
+
+// BanServiceAccount is not notified as banning service accounts is part of their rotation,
+// sa_exhausted is sent once a remote has no service accounts left.
+func BanServiceAccount(serviceAccountPath string) error {
+	_, err := ban(serviceAccountPath, serviceAccountBanHours(serviceAccountPath))
+	return err
+}
+
+func NextQuotaReset(t time.Time) time.Time {
+	l := t.In(quotaResetLocation)
+	return time.Date(l.Year(), l.Month(), l.Day()+1, 0, 0, 0, 0, quotaResetLocation)
+}
+
+/* Private */
+
+func ban(key string, hours int) (time.Time, error) {
+	banCfg := loaded().cfg.Rclone.Ban
+
+	// record the strike & ban with the cache open once
+	if err := cache.Acquire(); err != nil {
+		return time.Time{}, err
+	}
+	defer cache.Release()
+
+	// escalate repeat offenders
+	strikes := 1
+	if banCfg.Escalate {
+		s, err := cache.AddStrike(key, strikeResetAfter)
+		if err != nil {
+			log.WithError(err).Errorf("Failed recording strike for: %q", key)
+		} else {
+			strikes = s
+		}
+	}
+
+	expires := banExpires(banCfg, time.Now(), escalatedBanHours(banCfg, hours, strikes))
+
+	log.WithFields(logrus.Fields{
+		"expires": expires.UTC(),
+		"strikes": strikes,
+	}).Warnf("Banning %q", key)
+
+	return expires, cache.SetBanned(key, expires)
+}
+
+func escalatedBanHours(banCfg config.RcloneBanConfig, hours int, strikes int) int {
+	if !banCfg.Escalate {
+		return hours
+	}
+
+	maxHours := banCfg.MaxHours
+	if maxHours <= 0 {
+		maxHours = defaultBanMaxHours
+	}
+
+	// double the ban for each previous strike
+	for i := 1; i < strikes && hours < maxHours; i++ {
+		hours *= 2
+	}
+
+	if hours > maxHours {
+		hours = maxHours
+	}
+
+	return hours
+}
+
+func banExpires(banCfg config.RcloneBanConfig, now time.Time, hours int) time.Time {
+	if !banCfg.QuotaReset {
+		return now.Add(time.Duration(hours) * time.Hour)
+	}
+
+	// wait for (at least) the next quota reset, each additional 24 hours waits for another reset
+	resets := hours / 24
+	if resets < 1 {
+		resets = 1
+	}
+
+	return NextQuotaReset(now).AddDate(0, 0, resets-1)
+}
+
+func remoteBanHours(remote string) int {
+	banCfg := loaded().cfg.Rclone.Ban
+
+	if hours, ok := banCfg.Remotes[strings.TrimSuffix(remote, ":")]; ok && hours > 0 {
+		return hours
+	}
+
+	return defaultHours(banCfg.Hours)
+}
+
+func serviceAccountBanHours(serviceAccountPath string) int {
+	banCfg := loaded().cfg.Rclone.Ban
+
+	// use the most specific service account folder
+	hours := 0
+	matched := ""
+
+	for folder, folderHours := range banCfg.ServiceAccounts {
+		folder = filepath.Clean(folder)
+
+		rel, err := filepath.Rel(folder, serviceAccountPath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		if len(folder) > len(matched) && folderHours > 0 {
+			matched = folder
+			hours = folderHours
+		}
+	}
+
+	if hours > 0 {
+		return hours
+	}
+
+	return defaultHours(banCfg.Hours)
+}
+
+func defaultHours(hours int) int {
+	if hours > 0 {
+		return hours
+	}
+
+	return defaultBanHours
+}
+
+func loadQuotaResetLocation() *time.Location {
+	l, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		return time.FixedZone("PST", -8*60*60)
+	}
+
+	return l
+}
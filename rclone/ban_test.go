@@ -0,0 +1,77 @@
+package rclone
+
+import (
+	"github.com/l3uddz/crop/config"
+	"testing"
+	"time"
+)
+
+func TestEscalatedBanHours(t *testing.T) {
+	escalate := config.RcloneBanConfig{Escalate: true}
+
+	tests := []struct {
+		name    string
+		banCfg  config.RcloneBanConfig
+		hours   int
+		strikes int
+		want    int
+	}{
+		{"not escalating", config.RcloneBanConfig{}, 25, 3, 25},
+		{"first ban", escalate, 25, 1, 25},
+		{"second ban", escalate, 25, 2, 50},
+		{"third ban", escalate, 25, 3, 100},
+		{"capped at the default max", escalate, 25, 4, 168},
+		{"capped at max_hours", config.RcloneBanConfig{Escalate: true, MaxHours: 48}, 25, 3, 48},
+		{"longer than max_hours", config.RcloneBanConfig{Escalate: true, MaxHours: 12}, 25, 1, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escalatedBanHours(tt.banCfg, tt.hours, tt.strikes); got != tt.want {
+				t.Errorf("escalatedBanHours(%d, %d) = %d, want %d", tt.hours, tt.strikes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBanExpires(t *testing.T) {
+	quotaReset := config.RcloneBanConfig{QuotaReset: true}
+
+	tests := []struct {
+		name   string
+		banCfg config.RcloneBanConfig
+		now    time.Time
+		hours  int
+		want   time.Time
+	}{
+		{"fixed window", config.RcloneBanConfig{},
+			time.Date(2021, 12, 6, 20, 0, 0, 0, time.UTC), 25,
+			time.Date(2021, 12, 7, 21, 0, 0, 0, time.UTC)},
+		{"next reset", quotaReset,
+			time.Date(2021, 12, 6, 20, 0, 0, 0, time.UTC), 25,
+			time.Date(2021, 12, 7, 8, 0, 0, 0, time.UTC)},
+		{"less than a day waits for the next reset", quotaReset,
+			time.Date(2021, 12, 6, 20, 0, 0, 0, time.UTC), 12,
+			time.Date(2021, 12, 7, 8, 0, 0, 0, time.UTC)},
+		{"just after a reset", quotaReset,
+			time.Date(2021, 12, 7, 9, 0, 0, 0, time.UTC), 25,
+			time.Date(2021, 12, 8, 8, 0, 0, 0, time.UTC)},
+		{"additional days wait for another reset", quotaReset,
+			time.Date(2021, 12, 6, 20, 0, 0, 0, time.UTC), 50,
+			time.Date(2021, 12, 8, 8, 0, 0, 0, time.UTC)},
+		{"daylight saving time", quotaReset,
+			time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC), 25,
+			time.Date(2021, 7, 2, 7, 0, 0, 0, time.UTC)},
+		{"across the end of daylight saving time", quotaReset,
+			time.Date(2021, 11, 6, 12, 0, 0, 0, time.UTC), 48,
+			time.Date(2021, 11, 8, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := banExpires(tt.banCfg, tt.now, tt.hours); !got.Equal(tt.want) {
+				t.Errorf("banExpires(%v, %d) = %v, want %v", tt.now, tt.hours, got.UTC(), tt.want)
+			}
+		})
+	}
+}
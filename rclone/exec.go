@@ -0,0 +1,117 @@
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-cmd/cmd"
+	"syscall"
+	"time"
+)
+
+const (
+	// how long rclone is given to exit after SIGTERM, before it is killed
+	killTimeout = 10 * time.Second
+)
+
+/* Struct */
+
+// execExecutor spawns the rclone binary for every operation.
+type execExecutor struct{}
+
+/* Public */
+
+func (e *execExecutor) Name() string {
+	return BackendExec
+}
+
+func (e *execExecutor) Execute(ctx context.Context, op *Operation) (*Result, error) {
+	result := &Result{ExitCode: ExitSyntaxError}
+
+	// do not start when already cancelled
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	// generate required rclone parameters
+	params := append([]string{op.Command}, op.Paths...)
+	params = append(params, op.Params...)
+
+	// generate required rclone env
+	var rcloneEnv []string
+	for _, sa := range op.ServiceAccounts {
+		if sa == nil {
+			continue
+		}
+
+		rcloneEnv = append(rcloneEnv, fmt.Sprintf("%s=%s", sa.RemoteEnvVar, sa.ServiceAccountPath))
+	}
+
+	if len(rcloneEnv) > 0 {
+		op.Log.Debugf("Generated rclone env: %v", rcloneEnv)
+	}
+
+	// setup cmd
+	cmdOptions := cmd.Options{
+		Buffered:  false,
+		Streaming: true,
+	}
+	rcloneCmd := cmd.NewCmdOptions(cmdOptions, op.state.cfg.Rclone.Path, params...)
+	rcloneCmd.Env = rcloneEnv
+
+	// live stream logs
+	doneChan := streamOutput(rcloneCmd, op, result)
+
+	// run command
+	statusChan := rcloneCmd.Start()
+
+	var status cmd.Status
+	select {
+	case status = <-statusChan:
+	case <-ctx.Done():
+		status = stop(op, rcloneCmd, statusChan, ctx.Err())
+	}
+	<-doneChan
+
+	// check status
+	result.ExitCode = status.Exit
+	result.Elapsed = time.Duration(status.Runtime * float64(time.Second))
+
+	switch {
+	case ctx.Err() != nil:
+		return result, ctx.Err()
+	case status.Exit == ExitSuccess:
+		result.Success = true
+	default:
+		break
+	}
+
+	return result, status.Error
+}
+
+/* Private */
+
+func stop(op *Operation, rcloneCmd *cmd.Cmd, statusChan <-chan cmd.Status, reason error) cmd.Status {
+	// rclone runs in its own process group, so it does not see signals sent to crop
+	op.Log.WithError(reason).Warn("Stopping rclone...")
+
+	if err := rcloneCmd.Stop(); err != nil {
+		op.Log.WithError(err).Error("Failed sending SIGTERM to rclone")
+	}
+
+	select {
+	case status := <-statusChan:
+		return status
+	case <-time.After(killTimeout):
+		break
+	}
+
+	op.Log.Warnf("rclone did not stop within %v, killing...", killTimeout)
+
+	if pid := rcloneCmd.Status().PID; pid > 0 {
+		if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil {
+			op.Log.WithError(err).Error("Failed sending SIGKILL to rclone")
+		}
+	}
+
+	return <-statusChan
+}
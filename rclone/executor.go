@@ -0,0 +1,196 @@
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	BackendExec      = "exec"
+	BackendLibrclone = "librclone"
+	BackendRcd       = "rcd"
+)
+
+/* Interface */
+
+// Executor runs rclone operations, either by spawning rclone or by driving it in-process.
+type Executor interface {
+	Name() string
+	Execute(ctx context.Context, op *Operation) (*Result, error)
+}
+
+/* Struct */
+
+// Operation is a single rclone command, e.g. copy from -> to.
+type Operation struct {
+	Log             *logrus.Entry
+	Command         string
+	Paths           []string
+	Params          []string
+	ServiceAccounts []*RemoteServiceAccount
+
+	// the state when the operation was created
+	state *state
+}
+
+/* Var */
+
+var (
+	fallback Executor = &execExecutor{}
+
+	// returned by an executor that cannot perform an operation, it will be performed by the exec backend instead
+	errUnsupported = errors.New("unsupported by backend")
+)
+
+/* Public */
+
+func SupportedBackends() []string {
+	return []string{BackendExec, BackendLibrclone, BackendRcd}
+}
+
+/* Private */
+
+func (s *state) initExecutor() error {
+	// parse timeout
+	if s.cfg.Rclone.Timeout != "" {
+		d, err := time.ParseDuration(s.cfg.Rclone.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed parsing timeout: %w", err)
+		}
+		s.timeout = d
+	}
+
+	// parse grace period
+	if s.cfg.Rclone.GracePeriod != "" {
+		d, err := time.ParseDuration(s.cfg.Rclone.GracePeriod)
+		if err != nil {
+			return fmt.Errorf("failed parsing grace_period: %w", err)
+		}
+		s.gracePeriod = d
+	}
+
+	backend := strings.ToLower(s.cfg.Rclone.Backend)
+
+	switch backend {
+	case "", BackendExec:
+		s.executor = fallback
+	case BackendLibrclone:
+		e, err := newLibrcloneExecutor(s.cfg.Rclone.Config)
+		if err != nil {
+			return fmt.Errorf("failed initializing %s backend: %w", backend, err)
+		}
+		s.executor = e
+	case BackendRcd:
+		e, err := newRcdExecutor(s.cfg.Rclone.Rc)
+		if err != nil {
+			return fmt.Errorf("failed initializing %s backend: %w", backend, err)
+		}
+		s.executor = e
+	default:
+		return fmt.Errorf("unknown backend: %q", s.cfg.Rclone.Backend)
+	}
+
+	log.Debugf("Using rclone backend: %s", s.executor.Name())
+	return nil
+}
+
+func newOperation(rLog *logrus.Entry, command string, paths []string, serviceAccounts []*RemoteServiceAccount,
+	additionalRcloneParams []string) (*Operation, error) {
+	op := &Operation{
+		Log:             rLog,
+		Command:         command,
+		Paths:           paths,
+		ServiceAccounts: serviceAccounts,
+		state:           loaded(),
+	}
+
+	baseParams, err := getBaseParams(op.state.cfg)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed generating baseParams")
+	}
+	op.Params = append(op.Params, baseParams...)
+
+	additionalParams, err := getAdditionalParams(command, additionalRcloneParams)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed generating additionalParams")
+	}
+	op.Params = append(op.Params, additionalParams...)
+
+	return op, nil
+}
+
+func execute(ctx context.Context, op *Operation) (*Result, error) {
+	op.Log.Debugf("Generated params: %v", op.Params)
+	op.Log.Debug("Starting...")
+
+	// do not start when already cancelled
+	if err := ctx.Err(); err != nil {
+		return &Result{ExitCode: ExitSyntaxError}, err
+	}
+
+	// let a running operation finish within the grace period once cancelled
+	ctx, cancel := withGracePeriod(ctx, op)
+	defer cancel()
+
+	if op.state.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, op.state.timeout)
+		defer cancel()
+	}
+
+	result, err := op.state.executor.Execute(ctx, op)
+	if errors.Is(err, errUnsupported) && op.state.executor != fallback {
+		op.Log.WithError(err).Debugf("Falling back to the %s backend", fallback.Name())
+		result, err = fallback.Execute(ctx, op)
+	}
+
+	if result != nil {
+		for _, sa := range op.ServiceAccounts {
+			if sa != nil {
+				result.ServiceAccounts = append(result.ServiceAccounts, sa.ServiceAccountPath)
+			}
+		}
+
+		op.emit(result, &Event{Type: EventExit, Time: time.Now(), ExitCode: result.ExitCode})
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %v: %w", op.state.timeout, err)
+	}
+
+	return result, err
+}
+
+func withGracePeriod(parent context.Context, op *Operation) (context.Context, context.CancelFunc) {
+	gracePeriod := op.state.gracePeriod
+	if gracePeriod <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	// the operation is only cancelled once the grace period has passed
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-parent.Done():
+			break
+		}
+
+		op.Log.Infof("Waiting up to %v for the running operation to finish...", gracePeriod)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(gracePeriod):
+			op.Log.Warnf("Operation did not finish within %v", gracePeriod)
+			cancel()
+		}
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,142 @@
+package rclone
+
+import (
+	"regexp"
+	"time"
+)
+
+/* Const */
+
+type Failure int
+
+const (
+	FailureNone Failure = iota
+	FailureUnknown
+	FailureServerError
+	FailureUserRateLimit
+	FailureTransferLimit
+	FailureQuota
+	FailureDailyLimit
+	FailureTeamDriveFileLimit
+)
+
+type FailureAction int
+
+const (
+	ActionAbort FailureAction = iota
+	ActionRotate
+	ActionBackoff
+	ActionBanRemote
+)
+
+const (
+	MaxBackoffAttempts = 5
+
+	backoffInitial = 30 * time.Second
+	backoffMax     = 10 * time.Minute
+)
+
+/* Var */
+
+var (
+	// ordered by precedence, the first matching pattern is used
+	failurePatterns = []struct {
+		failure Failure
+		re      *regexp.Regexp
+	}{
+		{FailureTeamDriveFileLimit, regexp.MustCompile(`teamDriveFileLimitExceeded`)},
+		{FailureDailyLimit, regexp.MustCompile(`dailyLimitExceeded`)},
+		{FailureUserRateLimit, regexp.MustCompile(`userRateLimitExceeded`)},
+		{FailureTransferLimit, regexp.MustCompile(`Max transfer limit reached`)},
+		{FailureQuota, regexp.MustCompile(`(?i)(storageQuotaExceeded|quotaExceeded|Error 403:.*quota)`)},
+		{FailureServerError, regexp.MustCompile(`(Error 5\d\d|backendError|internalError)`)},
+	}
+)
+
+/* Public */
+
+func ClassifyMessage(msg string) Failure {
+	for _, p := range failurePatterns {
+		if p.re.MatchString(msg) {
+			return p.failure
+		}
+	}
+
+	return FailureNone
+}
+
+func (f Failure) Action() FailureAction {
+	switch f {
+	case FailureUserRateLimit, FailureTransferLimit, FailureQuota:
+		// the service account (or remote) has hit a limit, try another
+		return ActionRotate
+	case FailureServerError:
+		// google is having issues, try again shortly
+		return ActionBackoff
+	case FailureDailyLimit:
+		// the api quota for this remote is exhausted until it resets
+		return ActionBanRemote
+	default:
+		// teamDriveFileLimitExceeded & unknown failures cannot be fixed by retrying
+		return ActionAbort
+	}
+}
+
+func (f Failure) IsUploadLimit() bool {
+	return f == FailureUserRateLimit || f == FailureTransferLimit || f == FailureQuota
+}
+
+func (f Failure) String() string {
+	switch f {
+	case FailureNone:
+		return "none"
+	case FailureServerError:
+		return "server_error"
+	case FailureUserRateLimit:
+		return "user_rate_limit"
+	case FailureTransferLimit:
+		return "transfer_limit"
+	case FailureQuota:
+		return "quota_exceeded"
+	case FailureDailyLimit:
+		return "daily_limit"
+	case FailureTeamDriveFileLimit:
+		return "team_drive_file_limit"
+	default:
+		return "unknown"
+	}
+}
+
+func BackoffDuration(attempt int) time.Duration {
+	wait := backoffInitial
+	for i := 0; i < attempt && wait < backoffMax; i++ {
+		wait *= 2
+	}
+
+	if wait > backoffMax {
+		wait = backoffMax
+	}
+
+	return wait
+}
+
+/* Private */
+
+func (r *Result) classify() {
+	switch {
+	case r.Success:
+		r.Failure = FailureNone
+	case r.Failure != FailureNone:
+		// failure was determined from the log output
+		break
+	case r.ExitCode == ExitFatalError:
+		// --drive-stop-on-upload-limit exits with a fatal error when the upload limit is hit
+		r.Failure = FailureUserRateLimit
+	case r.ExitCode == ExitTransferExceeded:
+		r.Failure = FailureTransferLimit
+	default:
+		r.Failure = FailureUnknown
+	}
+
+	r.UploadLimit = r.Failure.IsUploadLimit()
+}
@@ -0,0 +1,58 @@
+package rclone
+
+import "testing"
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want Failure
+	}{
+		{"none", "Copied (new)", FailureNone},
+		{"user rate limit",
+			"Failed to copy: googleapi: Error 403: User rate limit exceeded., userRateLimitExceeded", FailureUserRateLimit},
+		{"transfer limit", "Max transfer limit reached as set by --max-transfer", FailureTransferLimit},
+		{"storage quota", "googleapi: Error 403: The user's Drive storage quota has been exceeded., storageQuotaExceeded",
+			FailureQuota},
+		{"quota case insensitive", "googleapi: Error 403: QuotaExceeded", FailureQuota},
+		{"403 quota", "googleapi: Error 403: Upload quota reached", FailureQuota},
+		{"daily limit", "googleapi: Error 403: Daily Limit Exceeded, dailyLimitExceeded", FailureDailyLimit},
+		{"team drive file limit",
+			"googleapi: Error 403: The file limit for this shared drive has been exceeded., teamDriveFileLimitExceeded",
+			FailureTeamDriveFileLimit},
+		{"server error", "googleapi: Error 503: Backend Error, backendError", FailureServerError},
+		{"internal error", "googleapi: Error 500: Internal Error, internalError", FailureServerError},
+		{"other 403", "googleapi: Error 403: Insufficient Permission", FailureNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyMessage(tt.msg); got != tt.want {
+				t.Errorf("ClassifyMessage(%q) = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailureAction(t *testing.T) {
+	tests := []struct {
+		failure Failure
+		want    FailureAction
+	}{
+		{FailureUserRateLimit, ActionRotate},
+		{FailureTransferLimit, ActionRotate},
+		{FailureQuota, ActionRotate},
+		{FailureServerError, ActionBackoff},
+		{FailureDailyLimit, ActionBanRemote},
+		{FailureTeamDriveFileLimit, ActionAbort},
+		{FailureUnknown, ActionAbort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.failure.String(), func(t *testing.T) {
+			if got := tt.failure.Action(); got != tt.want {
+				t.Errorf("Action() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
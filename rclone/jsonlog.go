@@ -0,0 +1,189 @@
+package rclone
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-cmd/cmd"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+/* Const */
+
+type EventType int
+
+const (
+	EventLog EventType = iota
+	EventTransfer
+	EventDelete
+	EventError
+	EventStats
+	EventUploadLimit
+	EventExit
+)
+
+/* Struct */
+
+type Event struct {
+	Type    EventType
+	Level   string
+	Message string
+	Object  string
+	Time    time.Time
+	Stats   *EventStatsBlock
+	Failure Failure
+
+	// set for EventExit
+	ExitCode int
+}
+
+type EventStatsBlock struct {
+	Bytes       int64   `json:"bytes"`
+	Checks      int     `json:"checks"`
+	Deletes     int     `json:"deletes"`
+	ElapsedTime float64 `json:"elapsedTime"`
+	Errors      int     `json:"errors"`
+	FatalError  bool    `json:"fatalError"`
+	LastError   string  `json:"lastError"`
+	Renames     int     `json:"renames"`
+	RetryError  bool    `json:"retryError"`
+	Speed       float64 `json:"speed"`
+	TotalBytes  int64   `json:"totalBytes"`
+	Transfers   int     `json:"transfers"`
+}
+
+type jsonLogLine struct {
+	Level  string           `json:"level"`
+	Msg    string           `json:"msg"`
+	Object string           `json:"object"`
+	Time   time.Time        `json:"time"`
+	Stats  *EventStatsBlock `json:"stats"`
+}
+
+/* Var */
+
+var (
+	transferMessages = []string{
+		"Copied (",
+		"Moved (",
+	}
+)
+
+/* Public */
+
+func ParseLogLine(line string) *Event {
+	var l jsonLogLine
+
+	// not a json log line (e.g. a panic), treat as a plain log message
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") || json.Unmarshal([]byte(line), &l) != nil {
+		return &Event{
+			Type:    EventLog,
+			Level:   "info",
+			Message: line,
+		}
+	}
+
+	e := &Event{
+		Type:    EventLog,
+		Level:   l.Level,
+		Message: strings.TrimSpace(l.Msg),
+		Object:  l.Object,
+		Time:    l.Time,
+		Stats:   l.Stats,
+	}
+
+	// determine event type
+	if e.Stats == nil && e.Level != "debug" {
+		e.Failure = ClassifyMessage(e.Message)
+	}
+
+	switch {
+	case e.Stats != nil:
+		e.Type = EventStats
+	case e.Failure.IsUploadLimit():
+		e.Type = EventUploadLimit
+	case e.Level == "error" || e.Level == "critical":
+		e.Type = EventError
+	case e.Object != "" && hasAnyPrefix(e.Message, transferMessages):
+		e.Type = EventTransfer
+	case e.Object != "" && e.Message == "Deleted":
+		e.Type = EventDelete
+	default:
+		break
+	}
+
+	return e
+}
+
+/* Private */
+
+func streamOutput(rcloneCmd *cmd.Cmd, op *Operation, result *Result) <-chan struct{} {
+	doneChan := make(chan struct{})
+
+	go func() {
+		defer close(doneChan)
+
+		for rcloneCmd.Stdout != nil || rcloneCmd.Stderr != nil {
+			select {
+			case line, open := <-rcloneCmd.Stdout:
+				if !open {
+					rcloneCmd.Stdout = nil
+					continue
+				}
+				handleLogLine(line, op, result)
+			case line, open := <-rcloneCmd.Stderr:
+				if !open {
+					rcloneCmd.Stderr = nil
+					continue
+				}
+				handleLogLine(line, op, result)
+			}
+		}
+	}()
+
+	return doneChan
+}
+
+func handleLogLine(line string, op *Operation, result *Result) {
+	e := ParseLogLine(line)
+	op.emit(result, e)
+
+	// log event (stats blocks span multiple lines)
+	level := logLevel(e.Level)
+
+	for _, msg := range strings.Split(e.Message, "\n") {
+		if msg = strings.TrimSpace(msg); msg == "" {
+			continue
+		}
+
+		if e.Object != "" {
+			msg = fmt.Sprintf("%s: %s", e.Object, msg)
+		}
+
+		log.Log(level, msg)
+	}
+}
+
+func logLevel(level string) logrus.Level {
+	switch level {
+	case "debug":
+		return logrus.DebugLevel
+	case "warning":
+		return logrus.WarnLevel
+	case "error", "critical", "alert", "emergency":
+		return logrus.ErrorLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
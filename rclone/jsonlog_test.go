@@ -0,0 +1,124 @@
+package rclone
+
+import "testing"
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		typ     EventType
+		level   string
+		message string
+		object  string
+		failure Failure
+		bytes   int64
+	}{
+		{
+			name:    "plain text",
+			line:    "panic: runtime error",
+			typ:     EventLog,
+			level:   "info",
+			message: "panic: runtime error",
+		},
+		{
+			name:    "invalid json",
+			line:    `{"level":"info"`,
+			typ:     EventLog,
+			level:   "info",
+			message: `{"level":"info"`,
+		},
+		{
+			name:    "info",
+			line:    `{"level":"info","msg":"There was nothing to transfer\n","time":"2021-12-01T10:00:00Z"}`,
+			typ:     EventLog,
+			level:   "info",
+			message: "There was nothing to transfer",
+		},
+		{
+			name:    "copied",
+			line:    `{"level":"info","msg":"Copied (new)\n","object":"tv/show.mkv","time":"2021-12-01T10:00:00Z"}`,
+			typ:     EventTransfer,
+			level:   "info",
+			message: "Copied (new)",
+			object:  "tv/show.mkv",
+		},
+		{
+			name:    "moved",
+			line:    `{"level":"info","msg":"Moved (server-side)","object":"a.mkv"}`,
+			typ:     EventTransfer,
+			level:   "info",
+			message: "Moved (server-side)",
+			object:  "a.mkv",
+		},
+		{
+			name:    "deleted",
+			line:    `{"level":"info","msg":"Deleted","object":"a.mkv"}`,
+			typ:     EventDelete,
+			level:   "info",
+			message: "Deleted",
+			object:  "a.mkv",
+		},
+		{
+			name:    "error",
+			line:    `{"level":"error","msg":"Failed to copy: permission denied","object":"a.mkv"}`,
+			typ:     EventError,
+			level:   "error",
+			message: "Failed to copy: permission denied",
+			object:  "a.mkv",
+		},
+		{
+			name: "upload limit",
+			line: `{"level":"error","msg":"Failed to copy: googleapi: Error 403: User rate limit exceeded., ` +
+				`userRateLimitExceeded","object":"a.mkv"}`,
+			typ:     EventUploadLimit,
+			level:   "error",
+			message: "Failed to copy: googleapi: Error 403: User rate limit exceeded., userRateLimitExceeded",
+			object:  "a.mkv",
+			failure: FailureUserRateLimit,
+		},
+		{
+			name:    "server error",
+			line:    `{"level":"error","msg":"googleapi: Error 500: Internal Error, internalError"}`,
+			typ:     EventError,
+			level:   "error",
+			message: "googleapi: Error 500: Internal Error, internalError",
+			failure: FailureServerError,
+		},
+		{
+			name:    "debug is not classified",
+			line:    `{"level":"debug","msg":"pacer: Rate limited, userRateLimitExceeded"}`,
+			typ:     EventLog,
+			level:   "debug",
+			message: "pacer: Rate limited, userRateLimitExceeded",
+		},
+		{
+			name:    "stats",
+			line:    `{"level":"info","msg":"\nTransferred: 1 / 1, 100%\n","stats":{"bytes":1024,"transfers":1}}`,
+			typ:     EventStats,
+			level:   "info",
+			message: "Transferred: 1 / 1, 100%",
+			bytes:   1024,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ParseLogLine(tt.line)
+
+			switch {
+			case e.Type != tt.typ:
+				t.Errorf("Type = %v, want %v", e.Type, tt.typ)
+			case e.Level != tt.level:
+				t.Errorf("Level = %q, want %q", e.Level, tt.level)
+			case e.Message != tt.message:
+				t.Errorf("Message = %q, want %q", e.Message, tt.message)
+			case e.Object != tt.object:
+				t.Errorf("Object = %q, want %q", e.Object, tt.object)
+			case e.Failure != tt.failure:
+				t.Errorf("Failure = %v, want %v", e.Failure, tt.failure)
+			case tt.bytes != 0 && (e.Stats == nil || e.Stats.Bytes != tt.bytes):
+				t.Errorf("Stats = %+v, want bytes %d", e.Stats, tt.bytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+//go:build librclone
+// +build librclone
+
+package rclone
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/config/configfile"
+	"github.com/rclone/rclone/librclone/librclone"
+	"net/http"
+	"sync"
+	"time"
+
+	// backends & rc calls
+	_ "github.com/rclone/rclone/backend/all"
+	_ "github.com/rclone/rclone/fs/operations"
+	_ "github.com/rclone/rclone/fs/sync"
+)
+
+const (
+	// in-process jobs are cheap to poll
+	librclonePollInterval = 500 * time.Millisecond
+)
+
+/* Struct */
+
+// librcloneTransport performs rc calls in-process.
+type librcloneTransport struct{}
+
+/* Var */
+
+var (
+	librcloneOnce sync.Once
+	librcloneMtx  sync.Mutex
+)
+
+/* Private */
+
+func newLibrcloneExecutor(configPath string) (Executor, error) {
+	librcloneMtx.Lock()
+	defer librcloneMtx.Unlock()
+
+	if err := config.SetConfigPath(configPath); err != nil {
+		return nil, fmt.Errorf("failed setting rclone config path: %w", err)
+	}
+
+	// rclone can only be initialized once per process, the config is re-read on reload
+	librcloneOnce.Do(librclone.Initialize)
+	configfile.Install()
+
+	if _, err := (librcloneTransport{}).call("config/listremotes", map[string]interface{}{}); err != nil {
+		return nil, fmt.Errorf("failed loading rclone config: %w", err)
+	}
+
+	return &rcExecutor{
+		name:         BackendLibrclone,
+		transport:    librcloneTransport{},
+		pollInterval: librclonePollInterval,
+	}, nil
+}
+
+func (t librcloneTransport) call(method string, in map[string]interface{}) (map[string]interface{}, error) {
+	input, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding %s input: %w", method, err)
+	}
+
+	output, status := librclone.RPC(method, string(input))
+
+	out := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(output), &out); err != nil {
+		return nil, fmt.Errorf("failed decoding %s output: %w", method, err)
+	}
+
+	if status != http.StatusOK {
+		e := &rcError{Status: status}
+		e.Message, _ = out["error"].(string)
+		return out, e
+	}
+
+	return out, nil
+}
@@ -0,0 +1,12 @@
+//go:build !librclone
+// +build !librclone
+
+package rclone
+
+import "errors"
+
+/* Private */
+
+func newLibrcloneExecutor(_ string) (Executor, error) {
+	return nil, errors.New("crop was built without librclone support (build with: -tags librclone)")
+}
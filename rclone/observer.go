@@ -0,0 +1,39 @@
+package rclone
+
+import (
+	"sync"
+)
+
+/* Struct */
+
+// Observer is notified of the events (transfers, errors, stats etc.) of every rclone operation.
+type Observer func(op *Operation, e *Event)
+
+/* Var */
+
+var (
+	observers   []Observer
+	observerMtx sync.RWMutex
+)
+
+/* Public */
+
+func AddObserver(o Observer) {
+	observerMtx.Lock()
+	defer observerMtx.Unlock()
+
+	observers = append(observers, o)
+}
+
+/* Private */
+
+func (op *Operation) emit(result *Result, e *Event) {
+	result.handleEvent(e)
+
+	observerMtx.RLock()
+	defer observerMtx.RUnlock()
+
+	for _, o := range observers {
+		o(op, e)
+	}
+}
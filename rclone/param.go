@@ -9,7 +9,7 @@ import (
 func GetGlobalParams(gp GlobalParamType, name string) []string {
 	var params []string
 
-	p, ok := cfg.Rclone.GlobalParams[name]
+	p, ok := loaded().cfg.Rclone.GlobalParams[name]
 	if !ok {
 		return params
 	}
@@ -34,7 +34,7 @@ func GetGlobalParams(gp GlobalParamType, name string) []string {
 
 /* Private */
 
-func getBaseParams() ([]string, error) {
+func getBaseParams(cfg *config.Configuration) ([]string, error) {
 	var params []string
 
 	// dry run
@@ -48,13 +48,15 @@ func getBaseParams() ([]string, error) {
 		"--config", cfg.Rclone.Config,
 		// verbose
 		"-v",
+		// json log (parsed into events)
+		"--use-json-log",
 		// user-agent
 		"--user-agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_14_4) AppleWebKit/537.36 (KHTML, like Gecko) "+
 			"Chrome/74.0.3729.131 Safari/537.36",
 	)
 
 	// add stats
-	if config.Config.Rclone.Stats != "" {
+	if cfg.Rclone.Stats != "" {
 		params = append(params,
 			// stats
 			"--stats", cfg.Rclone.Stats)
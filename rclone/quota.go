@@ -0,0 +1,97 @@
+package rclone
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/stringutils"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDailyQuota   = "750GiB"
+	defaultQuotaReserve = "10GiB"
+)
+
+/* Public */
+
+func QuotaDay(t time.Time) string {
+	return NextQuotaReset(t).AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+func ServiceAccountUsage(serviceAccountPath string) uint64 {
+	used := cache.GetUsage(serviceAccountPath, QuotaDay(time.Now()))
+	if used < 0 {
+		return 0
+	}
+
+	return uint64(used)
+}
+
+func ServiceAccountRemaining(serviceAccountPath string) uint64 {
+	dailyQuota := loaded().dailyQuota
+
+	used := ServiceAccountUsage(serviceAccountPath)
+	if used >= dailyQuota {
+		return 0
+	}
+
+	return dailyQuota - used
+}
+
+func DailyQuota() uint64 {
+	return loaded().dailyQuota
+}
+
+/* Private */
+
+func (s *state) initQuota() error {
+	daily := s.cfg.Rclone.Quota.Daily
+	if daily == "" {
+		daily = defaultDailyQuota
+	}
+
+	reserve := s.cfg.Rclone.Quota.Reserve
+	if reserve == "" {
+		reserve = defaultQuotaReserve
+	}
+
+	v, err := humanize.ParseBytes(daily)
+	if err != nil {
+		return fmt.Errorf("failed parsing daily quota %q: %w", daily, err)
+	}
+	s.dailyQuota = v
+
+	v, err = humanize.ParseBytes(reserve)
+	if err != nil {
+		return fmt.Errorf("failed parsing quota reserve %q: %w", reserve, err)
+	}
+	s.quotaReserve = v
+
+	return nil
+}
+
+func nearQuota(serviceAccountPath string) bool {
+	return ServiceAccountRemaining(serviceAccountPath) <= loaded().quotaReserve
+}
+
+func recordUsage(to string, serviceAccounts []*RemoteServiceAccount, bytes int64) {
+	if loaded().cfg.Rclone.DryRun || bytes <= 0 || len(serviceAccounts) == 0 {
+		return
+	}
+
+	// bytes count against the service account(s) of the destination remote
+	remoteName := strings.ToLower(stringutils.FromLeftUntil(to, ":"))
+	day := QuotaDay(time.Now())
+
+	for _, sa := range serviceAccounts {
+		if sa == nil || strings.ToLower(sa.RemoteName) != remoteName {
+			continue
+		}
+
+		if err := cache.AddUsage(sa.ServiceAccountPath, day, bytes); err != nil {
+			log.WithError(err).Errorf("Failed recording usage for service account: %q", sa.ServiceAccountPath)
+		}
+	}
+}
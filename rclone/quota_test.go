@@ -0,0 +1,101 @@
+package rclone
+
+import (
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuotaDay(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"before midnight pacific", time.Date(2021, 12, 7, 7, 59, 0, 0, time.UTC), "2021-12-06"},
+		{"after midnight pacific", time.Date(2021, 12, 7, 8, 0, 0, 0, time.UTC), "2021-12-07"},
+		{"daylight saving time", time.Date(2021, 7, 2, 7, 0, 0, 0, time.UTC), "2021-07-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuotaDay(tt.t); got != tt.want {
+				t.Errorf("QuotaDay(%v) = %q, want %q", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	initTestState(t, config.RcloneConfig{
+		Quota: config.RcloneQuotaConfig{Daily: "100B", Reserve: "10B"},
+	})
+
+	serviceAccounts := []*RemoteServiceAccount{
+		{RemoteName: "gdrive", ServiceAccountPath: "/opt/sa/1.json"},
+		{RemoteName: "other", ServiceAccountPath: "/opt/sa/2.json"},
+		nil,
+	}
+
+	steps := []struct {
+		name      string
+		to        string
+		bytes     int64
+		wantUsage map[string]uint64
+		wantNear  bool
+	}{
+		{"destination remote only", "GDrive:/Media", 60,
+			map[string]uint64{"/opt/sa/1.json": 60, "/opt/sa/2.json": 0}, false},
+		{"nothing transferred", "gdrive:/Media", 0,
+			map[string]uint64{"/opt/sa/1.json": 60}, false},
+		{"within the reserve", "gdrive:/Media", 35,
+			map[string]uint64{"/opt/sa/1.json": 95}, true},
+		{"over the quota", "gdrive:/Media", 10,
+			map[string]uint64{"/opt/sa/1.json": 105}, true},
+	}
+
+	for _, s := range steps {
+		recordUsage(s.to, serviceAccounts, s.bytes)
+
+		for path, want := range s.wantUsage {
+			if got := ServiceAccountUsage(path); got != want {
+				t.Errorf("%s: ServiceAccountUsage(%q) = %d, want %d", s.name, path, got, want)
+			}
+		}
+
+		if got := nearQuota("/opt/sa/1.json"); got != s.wantNear {
+			t.Errorf("%s: nearQuota() = %v, want %v", s.name, got, s.wantNear)
+		}
+	}
+
+	if got := ServiceAccountRemaining("/opt/sa/1.json"); got != 0 {
+		t.Errorf("ServiceAccountRemaining() = %d, want 0", got)
+	}
+}
+
+func TestRecordUsageDryRun(t *testing.T) {
+	initTestState(t, config.RcloneConfig{DryRun: true})
+
+	recordUsage("gdrive:/Media", []*RemoteServiceAccount{
+		{RemoteName: "gdrive", ServiceAccountPath: "/opt/sa/1.json"},
+	}, 60)
+
+	if got := ServiceAccountUsage("/opt/sa/1.json"); got != 0 {
+		t.Errorf("ServiceAccountUsage() = %d, want 0", got)
+	}
+}
+
+// initTestState initializes rclone with cfg & an empty cache.
+func initTestState(t *testing.T, cfg config.RcloneConfig) {
+	t.Helper()
+
+	if err := cache.Init(filepath.Join(t.TempDir(), "cache"), 0); err != nil {
+		t.Fatalf("cache.Init() error = %v", err)
+	}
+
+	if err := Init(&config.Configuration{Rclone: cfg}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+}
@@ -0,0 +1,582 @@
+package rclone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+/* Const */
+
+type rcOptionKind int
+
+const (
+	rcBool rcOptionKind = iota
+	rcInt
+	rcFloat
+	rcString
+	rcDuration
+	rcList
+)
+
+const (
+	// option blocks of a rc call
+	rcBlockIgnored = ""
+	rcBlockConfig  = "_config"
+	rcBlockFilter  = "_filter"
+	rcBlockCall    = "_call"
+)
+
+/* Interface */
+
+// rcTransport performs rc calls, in-process via librclone or over http to rclone rcd.
+type rcTransport interface {
+	call(method string, in map[string]interface{}) (map[string]interface{}, error)
+}
+
+/* Struct */
+
+// rcExecutor performs operations through the rclone remote control api.
+type rcExecutor struct {
+	name      string
+	transport rcTransport
+	groups    uint64
+
+	// operations are submitted as jobs, their status is polled every pollInterval
+	pollInterval time.Duration
+}
+
+// rcError is an error returned by a rc call (rather than by the transport).
+type rcError struct {
+	Status  int    `json:"status"`
+	Message string `json:"error"`
+}
+
+type rcOption struct {
+	block string
+	key   string
+	kind  rcOptionKind
+}
+
+/* Var */
+
+var (
+	// distinguishes the stats groups of this crop from those of another on a shared rcd
+	rcSession = time.Now().UnixNano()
+
+	rcMethods = map[string]string{
+		CmdCopy:       "sync/copy",
+		CmdMove:       "sync/move",
+		CmdSync:       "sync/sync",
+		CmdDeleteFile: "operations/deletefile",
+		CmdDeleteDir:  "operations/rmdir",
+		CmdDeleteDirs: "operations/rmdirs",
+		CmdDedupe:     "operations/dedupe",
+	}
+
+	// rclone flags that can be translated into rc options, anything else is performed by the exec backend
+	rcOptions = map[string]rcOption{
+		// handled by crop
+		"config":         {rcBlockIgnored, "", rcString},
+		"v":              {rcBlockIgnored, "", rcBool},
+		"verbose":        {rcBlockIgnored, "", rcBool},
+		"use-json-log":   {rcBlockIgnored, "", rcBool},
+		"stats":          {rcBlockIgnored, "", rcString},
+		"stats-one-line": {rcBlockIgnored, "", rcBool},
+		"progress":       {rcBlockIgnored, "", rcBool},
+		"P":              {rcBlockIgnored, "", rcBool},
+
+		// config
+		"dry-run":           {rcBlockConfig, "DryRun", rcBool},
+		"user-agent":        {rcBlockConfig, "UserAgent", rcString},
+		"transfers":         {rcBlockConfig, "Transfers", rcInt},
+		"checkers":          {rcBlockConfig, "Checkers", rcInt},
+		"max-transfer":      {rcBlockConfig, "MaxTransfer", rcString},
+		"max-duration":      {rcBlockConfig, "MaxDuration", rcDuration},
+		"cutoff-mode":       {rcBlockConfig, "CutoffMode", rcString},
+		"max-backlog":       {rcBlockConfig, "MaxBacklog", rcInt},
+		"max-delete":        {rcBlockConfig, "MaxDelete", rcInt},
+		"buffer-size":       {rcBlockConfig, "BufferSize", rcString},
+		"fast-list":         {rcBlockConfig, "UseListR", rcBool},
+		"no-traverse":       {rcBlockConfig, "NoTraverse", rcBool},
+		"check-first":       {rcBlockConfig, "CheckFirst", rcBool},
+		"checksum":          {rcBlockConfig, "CheckSum", rcBool},
+		"c":                 {rcBlockConfig, "CheckSum", rcBool},
+		"size-only":         {rcBlockConfig, "SizeOnly", rcBool},
+		"ignore-existing":   {rcBlockConfig, "IgnoreExisting", rcBool},
+		"update":            {rcBlockConfig, "UpdateOlder", rcBool},
+		"u":                 {rcBlockConfig, "UpdateOlder", rcBool},
+		"order-by":          {rcBlockConfig, "OrderBy", rcString},
+		"track-renames":     {rcBlockConfig, "TrackRenames", rcBool},
+		"low-level-retries": {rcBlockConfig, "LowLevelRetries", rcInt},
+		"timeout":           {rcBlockConfig, "Timeout", rcDuration},
+		"contimeout":        {rcBlockConfig, "ConnectTimeout", rcDuration},
+		"no-update-modtime": {rcBlockConfig, "NoUpdateModTime", rcBool},
+
+		// filter
+		"include":            {rcBlockFilter, "IncludeRule", rcList},
+		"exclude":            {rcBlockFilter, "ExcludeRule", rcList},
+		"filter":             {rcBlockFilter, "FilterRule", rcList},
+		"include-from":       {rcBlockFilter, "IncludeFrom", rcList},
+		"exclude-from":       {rcBlockFilter, "ExcludeFrom", rcList},
+		"filter-from":        {rcBlockFilter, "FilterFrom", rcList},
+		"files-from":         {rcBlockFilter, "FilesFrom", rcList},
+		"files-from-raw":     {rcBlockFilter, "FilesFromRaw", rcList},
+		"exclude-if-present": {rcBlockFilter, "ExcludeFile", rcString},
+		"min-age":            {rcBlockFilter, "MinAge", rcString},
+		"max-age":            {rcBlockFilter, "MaxAge", rcString},
+		"min-size":           {rcBlockFilter, "MinSize", rcString},
+		"max-size":           {rcBlockFilter, "MaxSize", rcString},
+		"delete-excluded":    {rcBlockFilter, "DeleteExcluded", rcBool},
+		"ignore-case":        {rcBlockFilter, "IgnoreCase", rcBool},
+
+		// parameters of the call itself
+		"create-empty-src-dirs": {rcBlockCall, "createEmptySrcDirs", rcBool},
+		"delete-empty-src-dirs": {rcBlockCall, "deleteEmptySrcDirs", rcBool},
+		"dedupe-mode":           {rcBlockCall, "mode", rcString},
+	}
+
+	// backend flags (e.g. --drive-chunk-size) are passed to the remote(s) as connection string parameters
+	rcBackendPrefixes = []string{
+		"drive-",
+	}
+)
+
+/* Public */
+
+func (e *rcExecutor) Name() string {
+	return e.name
+}
+
+func (e *rcExecutor) Execute(ctx context.Context, op *Operation) (*Result, error) {
+	result := &Result{ExitCode: ExitSyntaxError}
+
+	method, in, err := rcCall(op)
+	if err != nil {
+		return result, err
+	}
+
+	// do not start when already cancelled
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	// give this call its own stats
+	group := fmt.Sprintf("crop/%d/%d", rcSession, atomic.AddUint64(&e.groups, 1))
+	in["_group"] = group
+
+	// run call
+	start := time.Now()
+	callErr := e.run(ctx, op, result, method, in, group)
+	result.Elapsed = time.Since(start)
+
+	var rcErr *rcError
+	switch {
+	case callErr == nil, ctx.Err() != nil:
+		break
+	case !errors.As(callErr, &rcErr):
+		return result, errors.WithMessagef(callErr, "failed calling %s", method)
+	case rcErr.Status == http.StatusNotFound && strings.HasPrefix(rcErr.Message, "couldn't find method"):
+		// e.g. an older rclone
+		return result, fmt.Errorf("%w: %s", errUnsupported, method)
+	}
+
+	// retrieve stats
+	stats, err := e.stats(group)
+	if err != nil {
+		op.Log.WithError(err).Warn("Failed retrieving stats")
+	} else {
+		op.emit(result, &Event{Type: EventStats, Level: "info", Time: time.Now(), Stats: stats})
+	}
+
+	// check status
+	switch {
+	case ctx.Err() != nil:
+		return result, ctx.Err()
+	case rcErr == nil:
+		result.ExitCode = ExitSuccess
+		result.Success = true
+	default:
+		op.emit(result, &Event{
+			Type:    EventError,
+			Level:   "error",
+			Message: rcErr.Message,
+			Time:    time.Now(),
+			Failure: ClassifyMessage(rcErr.Message),
+		})
+		result.ExitCode = rcExitCode(rcErr, stats)
+
+		op.Log.Errorf("%s: %s", method, rcErr.Message)
+	}
+
+	return result, nil
+}
+
+func (e *rcError) Error() string {
+	return fmt.Sprintf("rc error (status %d): %s", e.Status, e.Message)
+}
+
+/* Private */
+
+func (e *rcExecutor) run(ctx context.Context, op *Operation, result *Result, method string,
+	in map[string]interface{}, group string) error {
+	// submit job
+	in["_async"] = true
+
+	out, err := e.transport.call(method, in)
+	if err != nil {
+		return err
+	}
+
+	jobID, ok := out["jobid"]
+	if !ok {
+		return fmt.Errorf("no jobid returned by %s", method)
+	}
+
+	jLog := op.Log.WithField("job_id", jobID)
+	jLog.Debug("Submitted job")
+
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the job would otherwise keep running
+			jLog.WithError(ctx.Err()).Warn("Stopping job...")
+
+			if _, err := e.transport.call("job/stop", map[string]interface{}{"jobid": jobID}); err != nil {
+				jLog.WithError(err).Error("Failed stopping job")
+			}
+
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		status, err := e.transport.call("job/status", map[string]interface{}{"jobid": jobID})
+		if err != nil {
+			return errors.WithMessage(err, "failed polling job status")
+		}
+
+		if finished, _ := status["finished"].(bool); !finished {
+			e.progress(op, jLog, result, group)
+			continue
+		}
+
+		if success, _ := status["success"].(bool); success {
+			return nil
+		}
+
+		msg, _ := status["error"].(string)
+		return &rcError{Status: http.StatusInternalServerError, Message: msg}
+	}
+}
+
+func (e *rcExecutor) progress(op *Operation, jLog *logrus.Entry, result *Result, group string) {
+	out, err := e.transport.call("core/stats", map[string]interface{}{"group": group})
+	if err != nil {
+		jLog.WithError(err).Trace("Failed retrieving progress")
+		return
+	}
+
+	stats, err := decodeRcStats(out)
+	if err != nil {
+		jLog.WithError(err).Trace("Failed decoding progress")
+		return
+	}
+
+	op.emit(result, &Event{Type: EventStats, Level: "info", Time: time.Now(), Stats: stats})
+
+	percent := 0
+	if stats.TotalBytes > 0 {
+		percent = int(stats.Bytes * 100 / stats.TotalBytes)
+	}
+
+	jLog.Infof("Transferred: %s / %s, %d%%, %s/s, errors: %d", humanize.IBytes(uint64(stats.Bytes)),
+		humanize.IBytes(uint64(stats.TotalBytes)), percent, humanize.IBytes(uint64(stats.Speed)), stats.Errors)
+}
+
+func (e *rcExecutor) stats(group string) (*EventStatsBlock, error) {
+	out, err := e.transport.call("core/stats", map[string]interface{}{"group": group})
+	if err != nil {
+		return nil, err
+	}
+
+	// stats are no longer needed once retrieved
+	if _, err := e.transport.call("core/stats-delete", map[string]interface{}{"group": group}); err != nil {
+		log.WithError(err).Tracef("Failed deleting stats group: %q", group)
+	}
+
+	return decodeRcStats(out)
+}
+
+func decodeRcStats(out map[string]interface{}) (*EventStatsBlock, error) {
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := new(EventStatsBlock)
+	if err := json.Unmarshal(b, stats); err != nil {
+		return nil, fmt.Errorf("failed decoding stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func rcExitCode(e *rcError, stats *EventStatsBlock) int {
+	// mirrors how rclone determines its exit code
+	msg := strings.ToLower(e.Message)
+
+	switch {
+	case stats != nil && stats.FatalError:
+		return ExitFatalError
+	case strings.Contains(msg, "max transfer limit reached"):
+		return ExitTransferExceeded
+	case strings.Contains(msg, "directory not found"):
+		return ExitDirectoryNotFound
+	case strings.Contains(msg, "object not found"):
+		return ExitFileNotFound
+	case stats != nil && stats.RetryError:
+		return ExitTemporaryError
+	default:
+		return ExitErrorUnknown
+	}
+}
+
+func rcCall(op *Operation) (string, map[string]interface{}, error) {
+	method, ok := rcMethods[op.Command]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", errUnsupported, op.Command)
+	}
+
+	in := make(map[string]interface{})
+	options := map[string]map[string]interface{}{
+		rcBlockConfig: make(map[string]interface{}),
+		rcBlockFilter: make(map[string]interface{}),
+		rcBlockCall:   in,
+	}
+
+	// translate flags
+	backendParams, err := rcTranslateParams(op.Params, options)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, block := range []string{rcBlockConfig, rcBlockFilter} {
+		if len(options[block]) > 0 {
+			in[block] = options[block]
+		}
+	}
+
+	// remote(s)
+	paths := make([]string, 0, len(op.Paths))
+	for _, p := range op.Paths {
+		paths = append(paths, rcConnectionString(p, op.ServiceAccounts, backendParams))
+	}
+
+	switch op.Command {
+	case CmdCopy, CmdMove, CmdSync:
+		if len(paths) != 2 {
+			return "", nil, fmt.Errorf("%s requires a source and destination", op.Command)
+		}
+
+		in["srcFs"] = paths[0]
+		in["dstFs"] = paths[1]
+	case CmdDeleteDirs, CmdDedupe:
+		if len(paths) != 1 {
+			return "", nil, fmt.Errorf("%s requires a path", op.Command)
+		}
+
+		in["fs"] = paths[0]
+	default:
+		if len(op.Paths) != 1 {
+			return "", nil, fmt.Errorf("%s requires a path", op.Command)
+		}
+
+		fsPath, remote := rcSplitPath(op.Paths[0])
+		in["fs"] = rcConnectionString(fsPath, op.ServiceAccounts, backendParams)
+		in["remote"] = remote
+	}
+
+	return method, in, nil
+}
+
+func rcTranslateParams(params []string, options map[string]map[string]interface{}) (map[string]string, error) {
+	backendParams := make(map[string]string)
+
+	for i := 0; i < len(params); i++ {
+		param := params[i]
+		if !strings.HasPrefix(param, "-") {
+			return nil, fmt.Errorf("%w: unexpected argument %q", errUnsupported, param)
+		}
+
+		name := strings.TrimLeft(param, "-")
+		value, hasValue := "", false
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, value, hasValue = name[:idx], name[idx+1:], true
+		}
+
+		// takes the next param as its value when none was given with =
+		nextValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+
+			if i+1 >= len(params) {
+				return "", fmt.Errorf("flag needs a value: %s", param)
+			}
+
+			i++
+			return params[i], nil
+		}
+
+		// backend flag
+		if prefix, ok := rcBackendPrefix(name); ok {
+			key := strings.ReplaceAll(strings.TrimPrefix(name, prefix), "-", "_")
+
+			switch {
+			case hasValue:
+				backendParams[key] = value
+			case i+1 < len(params) && !strings.HasPrefix(params[i+1], "-"):
+				i++
+				backendParams[key] = params[i]
+			default:
+				backendParams[key] = "true"
+			}
+			continue
+		}
+
+		opt, ok := rcOptions[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: flag %s", errUnsupported, param)
+		}
+
+		var v interface{}
+
+		switch opt.kind {
+		case rcBool:
+			b := true
+			if hasValue {
+				parsed, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %w", param, err)
+				}
+				b = parsed
+			}
+			v = b
+		default:
+			s, err := nextValue()
+			if err != nil {
+				return nil, err
+			}
+
+			switch opt.kind {
+			case rcInt:
+				n, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %w", param, err)
+				}
+				v = n
+			case rcFloat:
+				f, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %w", param, err)
+				}
+				v = f
+			case rcDuration:
+				// time.Duration options are passed in nanoseconds
+				d, err := time.ParseDuration(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %w", param, err)
+				}
+				v = d.Nanoseconds()
+			case rcList:
+				existing, _ := options[opt.block][opt.key].([]string)
+				v = append(existing, s)
+			default:
+				v = s
+			}
+		}
+
+		if opt.block == rcBlockIgnored {
+			continue
+		}
+
+		options[opt.block][opt.key] = v
+	}
+
+	return backendParams, nil
+}
+
+func rcBackendPrefix(name string) (string, bool) {
+	for _, prefix := range rcBackendPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return prefix, true
+		}
+	}
+
+	return "", false
+}
+
+func rcConnectionString(p string, serviceAccounts []*RemoteServiceAccount, backendParams map[string]string) string {
+	idx := strings.Index(p, ":")
+	if idx < 0 {
+		// local path
+		return p
+	}
+
+	remote, remotePath := p[:idx], p[idx+1:]
+	remoteName := strings.SplitN(remote, ",", 2)[0]
+
+	params := make(map[string]string, len(backendParams)+1)
+	for k, v := range backendParams {
+		params[k] = v
+	}
+
+	// service accounts are set per remote, rather than via the environment
+	for _, sa := range serviceAccounts {
+		if sa != nil && strings.EqualFold(sa.RemoteName, remoteName) {
+			params["service_account_file"] = sa.ServiceAccountPath
+		}
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(remote)
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf(",%s='%s'", k, strings.ReplaceAll(params[k], "'", "''")))
+	}
+	sb.WriteString(":")
+	sb.WriteString(remotePath)
+
+	return sb.String()
+}
+
+func rcSplitPath(p string) (string, string) {
+	idx := strings.Index(p, ":")
+	if idx < 0 {
+		// local path
+		return path.Dir(p), path.Base(p)
+	}
+
+	remote, remotePath := p[:idx+1], strings.Trim(p[idx+1:], "/")
+	dir, file := path.Split(remotePath)
+
+	return remote + strings.TrimSuffix(dir, "/"), file
+}
@@ -0,0 +1,167 @@
+package rclone
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRcTranslateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  []string
+		config  map[string]interface{}
+		filter  map[string]interface{}
+		call    map[string]interface{}
+		backend map[string]string
+		wantErr error
+	}{
+		{
+			name:   "ignored",
+			params: []string{"--config", "/opt/rclone.conf", "-v", "--use-json-log", "--stats", "1m", "-P"},
+		},
+		{
+			name:   "bool",
+			params: []string{"--dry-run", "--fast-list=false", "-c"},
+			config: map[string]interface{}{"DryRun": true, "UseListR": false, "CheckSum": true},
+		},
+		{
+			name:   "int & string",
+			params: []string{"--transfers", "8", "--checkers=16", "--user-agent", "crop"},
+			config: map[string]interface{}{"Transfers": int64(8), "Checkers": int64(16), "UserAgent": "crop"},
+		},
+		{
+			name:   "duration in nanoseconds",
+			params: []string{"--timeout", "5m", "--max-duration=1h"},
+			config: map[string]interface{}{"Timeout": int64(300000000000), "MaxDuration": int64(3600000000000)},
+		},
+		{
+			name: "filters",
+			params: []string{"--exclude", "*.partial~", "--exclude=*.tmp", "--min-age", "30m",
+				"--files-from-raw", "/tmp/f.txt"},
+			filter: map[string]interface{}{
+				"ExcludeRule":  []string{"*.partial~", "*.tmp"},
+				"MinAge":       "30m",
+				"FilesFromRaw": []string{"/tmp/f.txt"},
+			},
+		},
+		{
+			name:   "call parameters",
+			params: []string{"--delete-empty-src-dirs", "--dedupe-mode", "newest"},
+			call:   map[string]interface{}{"deleteEmptySrcDirs": true, "mode": "newest"},
+		},
+		{
+			name:    "backend flags",
+			params:  []string{"--drive-chunk-size", "64M", "--drive-stop-on-upload-limit", "--drive-use-trash=false"},
+			backend: map[string]string{"chunk_size": "64M", "stop_on_upload_limit": "true", "use_trash": "false"},
+		},
+		{
+			name:    "backend flag followed by a flag",
+			params:  []string{"--drive-server-side-across-configs", "--dry-run"},
+			config:  map[string]interface{}{"DryRun": true},
+			backend: map[string]string{"server_side_across_configs": "true"},
+		},
+		{
+			name:    "unsupported flag",
+			params:  []string{"--bwlimit", "08:00,1M"},
+			wantErr: errUnsupported,
+		},
+		{
+			name:    "unexpected argument",
+			params:  []string{"extra"},
+			wantErr: errUnsupported,
+		},
+		{
+			name:    "missing value",
+			params:  []string{"--transfers"},
+			wantErr: errors.New("flag needs a value: --transfers"),
+		},
+		{
+			name:    "invalid int",
+			params:  []string{"--transfers", "many"},
+			wantErr: errors.New("invalid value for --transfers"),
+		},
+		{
+			name:    "invalid bool",
+			params:  []string{"--dry-run=maybe"},
+			wantErr: errors.New("invalid value for --dry-run=maybe"),
+		},
+		{
+			name:    "invalid duration",
+			params:  []string{"--timeout", "5"},
+			wantErr: errors.New("invalid value for --timeout"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := map[string]map[string]interface{}{
+				rcBlockConfig: make(map[string]interface{}),
+				rcBlockFilter: make(map[string]interface{}),
+				rcBlockCall:   make(map[string]interface{}),
+			}
+
+			backend, err := rcTranslateParams(tt.params, options)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("rcTranslateParams(%v) error = %v", tt.params, err)
+			case tt.wantErr != nil:
+				if err == nil || !errors.Is(err, tt.wantErr) && !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+					t.Fatalf("rcTranslateParams(%v) error = %v, want %v", tt.params, err, tt.wantErr)
+				}
+				return
+			}
+
+			for block, want := range map[string]map[string]interface{}{
+				rcBlockConfig: tt.config,
+				rcBlockFilter: tt.filter,
+				rcBlockCall:   tt.call,
+			} {
+				if want == nil {
+					want = make(map[string]interface{})
+				}
+
+				if !reflect.DeepEqual(options[block], want) {
+					t.Errorf("%s = %v, want %v", block, options[block], want)
+				}
+			}
+
+			if tt.backend == nil {
+				tt.backend = make(map[string]string)
+			}
+
+			if !reflect.DeepEqual(backend, tt.backend) {
+				t.Errorf("backend params = %v, want %v", backend, tt.backend)
+			}
+		})
+	}
+}
+
+func TestRcConnectionString(t *testing.T) {
+	serviceAccounts := []*RemoteServiceAccount{
+		{RemoteName: "gdrive", ServiceAccountPath: "/opt/sa/1.json"},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		backend map[string]string
+		want    string
+	}{
+		{"local path", "/mnt/local", map[string]string{"chunk_size": "64M"}, "/mnt/local"},
+		{"service account", "gdrive:/Media", nil, "gdrive,service_account_file='/opt/sa/1.json':/Media"},
+		{"other remote", "other:/Media", nil, "other:/Media"},
+		{"backend params sorted", "other:", map[string]string{"use_trash": "false", "chunk_size": "64M"},
+			"other,chunk_size='64M',use_trash='false':"},
+		{"quoted value", "other:/", map[string]string{"root_folder_id": "it's"}, "other,root_folder_id='it''s':/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rcConnectionString(tt.path, serviceAccounts, tt.backend); got != tt.want {
+				t.Errorf("rcConnectionString(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package rclone
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/l3uddz/crop/config"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	rcdDefaultPollInterval = 5 * time.Second
+	rcdRequestTimeout      = 30 * time.Second
+)
+
+/* Struct */
+
+// rcdTransport performs rc calls against a running rclone rcd.
+type rcdTransport struct {
+	url    string
+	user   string
+	pass   string
+	client *http.Client
+}
+
+/* Private */
+
+func newRcdExecutor(rc config.RcloneRcConfig) (Executor, error) {
+	u, err := url.Parse(rc.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid rc url: %q", rc.URL)
+	}
+
+	pollInterval := rcdDefaultPollInterval
+	if rc.PollInterval != "" {
+		pollInterval, err = time.ParseDuration(rc.PollInterval)
+		if err != nil || pollInterval <= 0 {
+			return nil, fmt.Errorf("invalid rc poll_interval: %q", rc.PollInterval)
+		}
+	}
+
+	t := &rcdTransport{
+		url:    strings.TrimSuffix(u.String(), "/"),
+		user:   rc.User,
+		pass:   rc.Pass,
+		client: &http.Client{Timeout: rcdRequestTimeout},
+	}
+
+	// the rcd may not be running yet, operations will fail until it is
+	if out, err := t.call("core/version", map[string]interface{}{}); err != nil {
+		log.WithError(err).Warnf("Failed connecting to rclone rcd: %s", t.url)
+	} else if _, err := t.call("rc/noopauth", map[string]interface{}{}); err != nil {
+		log.WithError(err).Warnf("Failed authenticating with rclone rcd: %s", t.url)
+	} else {
+		log.Debugf("Connected to rclone rcd %v: %s", out["version"], t.url)
+	}
+
+	return &rcExecutor{
+		name:         BackendRcd,
+		transport:    t,
+		pollInterval: pollInterval,
+	}, nil
+}
+
+func (t *rcdTransport) call(method string, in map[string]interface{}) (map[string]interface{}, error) {
+	input, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding %s input: %w", method, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url+"/"+method, bytes.NewReader(input))
+	if err != nil {
+		return nil, fmt.Errorf("failed creating %s request: %w", method, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if t.user != "" || t.pass != "" {
+		req.SetBasicAuth(t.user, t.pass)
+	}
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed requesting %s: %w", method, err)
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading %s response: %w", method, err)
+	}
+
+	out := make(map[string]interface{})
+	if err := json.Unmarshal(b, &out); err != nil {
+		if res.StatusCode != http.StatusOK {
+			// e.g. an authentication failure
+			return nil, &rcError{Status: res.StatusCode, Message: strings.TrimSpace(string(b))}
+		}
+
+		return nil, fmt.Errorf("failed decoding %s response: %w", method, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		e := &rcError{Status: res.StatusCode}
+		e.Message, _ = out["error"].(string)
+		return out, e
+	}
+
+	return out, nil
+}
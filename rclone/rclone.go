@@ -3,13 +3,15 @@ package rclone
 import (
 	"github.com/l3uddz/crop/config"
 	"github.com/l3uddz/crop/logger"
+	"sync/atomic"
+	"time"
 )
 
 var (
 	log = logger.GetLogger("rclone")
 
-	// init
-	cfg *config.Configuration
+	// init, swapped as a whole when the config is reloaded
+	current atomic.Value
 )
 
 /* Struct */
@@ -20,12 +22,58 @@ type RemoteInstruction struct {
 	ServerSide bool
 }
 
+// state is set on init, a running operation keeps the state it was created with.
+type state struct {
+	cfg      *config.Configuration
+	executor Executor
+
+	// maximum duration of a single operation (0 = unlimited)
+	timeout time.Duration
+
+	// how long a running operation is given to finish once cancelled (0 = stop immediately)
+	gracePeriod time.Duration
+
+	dailyQuota   uint64
+	quotaReserve uint64
+}
+
 /* Public */
 
 func Init(c *config.Configuration) error {
-	// set required globals
-	cfg = c
+	s := &state{
+		cfg:      c,
+		executor: fallback,
+	}
+
+	// validate service account strategies
+	if err := validateStrategies(c.Rclone.ServiceAccountRemotes); err != nil {
+		return err
+	}
 
-	// load service files for all uploader(s)
+	// parse service account quota
+	if err := s.initQuota(); err != nil {
+		return err
+	}
+
+	// setup backend
+	if err := s.initExecutor(); err != nil {
+		return err
+	}
+
+	current.Store(s)
 	return nil
 }
+
+/* Private */
+
+func loaded() *state {
+	if s, ok := current.Load().(*state); ok {
+		return s
+	}
+
+	// not initialized
+	return &state{
+		cfg:      &config.Configuration{},
+		executor: fallback,
+	}
+}
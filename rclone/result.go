@@ -0,0 +1,102 @@
+package rclone
+
+import (
+	"time"
+)
+
+/* Struct */
+
+type Stats struct {
+	Bytes     int64
+	Transfers int
+	Checks    int
+	Deletes   int
+	Renames   int
+	Errors    int
+	Elapsed   time.Duration
+
+	// service account files used
+	ServiceAccounts []string
+}
+
+type Result struct {
+	Stats
+
+	Success     bool
+	ExitCode    int
+	UploadLimit bool
+	Failure     Failure
+	LastError   string
+
+	// internal
+	seenStats bool
+}
+
+/* Public */
+
+func (s *Stats) Add(other Stats) {
+	s.Bytes += other.Bytes
+	s.Transfers += other.Transfers
+	s.Checks += other.Checks
+	s.Deletes += other.Deletes
+	s.Renames += other.Renames
+	s.Errors += other.Errors
+	s.Elapsed += other.Elapsed
+
+	for _, sa := range other.ServiceAccounts {
+		seen := false
+		for _, existing := range s.ServiceAccounts {
+			if existing == sa {
+				seen = true
+				break
+			}
+		}
+
+		if !seen {
+			s.ServiceAccounts = append(s.ServiceAccounts, sa)
+		}
+	}
+}
+
+/* Private */
+
+func (r *Result) handleEvent(e *Event) {
+	// keep the most severe failure seen
+	if e.Failure > r.Failure {
+		r.Failure = e.Failure
+	}
+
+	switch e.Type {
+	case EventStats:
+		// rclone stats are cumulative, the latest block is the most accurate
+		r.seenStats = true
+		r.Bytes = e.Stats.Bytes
+		r.Transfers = e.Stats.Transfers
+		r.Checks = e.Stats.Checks
+		r.Deletes = e.Stats.Deletes
+		r.Renames = e.Stats.Renames
+		r.Errors = e.Stats.Errors
+
+		if e.Stats.LastError != "" {
+			r.LastError = e.Stats.LastError
+		}
+	case EventTransfer:
+		if !r.seenStats {
+			r.Transfers++
+		}
+	case EventDelete:
+		if !r.seenStats {
+			r.Deletes++
+		}
+	case EventError:
+		if !r.seenStats {
+			r.Errors++
+		}
+
+		r.LastError = e.Message
+	case EventUploadLimit:
+		r.LastError = e.Message
+	default:
+		break
+	}
+}
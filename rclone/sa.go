@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"github.com/ReneKroon/ttlcache/v2"
 	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
 	"github.com/l3uddz/crop/logger"
 	"github.com/l3uddz/crop/maputils"
+	"github.com/l3uddz/crop/notify"
 	"github.com/l3uddz/crop/pathutils"
 	"github.com/l3uddz/crop/reutils"
 	"github.com/l3uddz/crop/stringutils"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"go/types"
 	"math/rand"
@@ -27,14 +30,27 @@ type RemoteServiceAccounts struct {
 }
 
 type RemoteServiceAccount struct {
+	RemoteName         string
 	RemoteEnvVar       string
 	ServiceAccountPath string
 }
 
+type RemoteCapacity struct {
+	Remote          string `json:"remote"`
+	ServiceAccounts int    `json:"service_accounts"`
+	Available       int    `json:"available"`
+	Banned          int    `json:"banned"`
+	Exhausted       int    `json:"exhausted"`
+	Used            uint64 `json:"used"`
+	Remaining       uint64 `json:"remaining"`
+}
+
 type ServiceAccountManager struct {
 	log                         *logrus.Entry
 	remoteServiceAccountFolders map[string][]string
 	remoteServiceAccounts       map[string]RemoteServiceAccounts
+	remoteStrategies            map[string]SelectionStrategy
+	clientEmails                map[string]string
 	parallelism                 int
 }
 
@@ -46,6 +62,8 @@ var (
 /* Private */
 
 func init() {
+	rand.Seed(time.Now().UnixNano())
+
 	mcache = ttlcache.NewCache()
 	_ = mcache.SetTTL(60 * time.Minute)
 	mcache.SetExpirationCallback(mcacheItemExpired)
@@ -63,16 +81,45 @@ func addServiceAccountsToTempCache(serviceAccounts []*RemoteServiceAccount) {
 
 /* Public */
 
-func NewServiceAccountManager(serviceAccountFolders map[string][]string, parallelism int) *ServiceAccountManager {
-	return &ServiceAccountManager{
+func NewServiceAccountManager(serviceAccountRemotes map[string][]config.ServiceAccountRemote,
+	parallelism int) *ServiceAccountManager {
+	m := &ServiceAccountManager{
 		log:                         logger.GetLogger("sa_manager"),
-		remoteServiceAccountFolders: serviceAccountFolders,
+		remoteServiceAccountFolders: make(map[string][]string),
 		remoteServiceAccounts:       make(map[string]RemoteServiceAccounts),
+		remoteStrategies:            make(map[string]SelectionStrategy),
+		clientEmails:                make(map[string]string),
 		parallelism:                 parallelism,
 	}
+
+	// parse service account folder(s) & selection strategy of each remote
+	for folder, remotes := range serviceAccountRemotes {
+		for _, remote := range remotes {
+			m.remoteServiceAccountFolders[folder] = append(m.remoteServiceAccountFolders[folder], remote.Remote)
+
+			if remote.Strategy == "" {
+				continue
+			}
+
+			// unknown strategies are rejected on init
+			strategy, _ := NewSelectionStrategy(remote.Strategy)
+			m.remoteStrategies[strings.ToLower(remote.Remote)] = strategy
+		}
+	}
+
+	return m
 }
 
 func (m *ServiceAccountManager) LoadServiceAccounts(remotePaths []string) error {
+	return m.loadServiceAccounts(remotePaths, true)
+}
+
+// ListServiceAccounts loads the service accounts without validating (or quarantining) them, e.g. for reporting.
+func (m *ServiceAccountManager) ListServiceAccounts(remotePaths []string) error {
+	return m.loadServiceAccounts(remotePaths, false)
+}
+
+func (m *ServiceAccountManager) loadServiceAccounts(remotePaths []string, validate bool) error {
 	m.log.Trace("Loading service accounts")
 
 	// iterate remotes
@@ -109,6 +156,11 @@ func (m *ServiceAccountManager) LoadServiceAccounts(remotePaths []string) error
 						return nil
 					}
 
+					// ignore quarantined files
+					if isQuarantined(remoteServiceAccountFolder, path) {
+						return nil
+					}
+
 					return &path
 				})
 
@@ -135,6 +187,11 @@ func (m *ServiceAccountManager) LoadServiceAccounts(remotePaths []string) error
 				return in < jn
 			})
 
+			// validate service files
+			if validate {
+				serviceAccountFiles = m.validateServiceAccounts(remoteServiceAccountFolder, serviceAccountFiles)
+			}
+
 			totalServiceAccountFiles = append(totalServiceAccountFiles, serviceAccountFiles...)
 		}
 
@@ -176,7 +233,6 @@ func (m *ServiceAccountManager) GetRandomServiceAccount(remotePath string) (stri
 	}
 
 	// random service account
-	rand.Seed(time.Now().Unix())
 	sa := remote.ServiceAccounts[rand.Intn(len(remote.ServiceAccounts))]
 
 	return sa.RealPath, nil
@@ -191,6 +247,12 @@ func (m *ServiceAccountManager) GetServiceAccount(remotePaths ...string) ([]*Rem
 	mtx.Lock()
 	defer mtx.Unlock()
 
+	// hold the cache open while checking for banned service accounts
+	if err := cache.Acquire(); err != nil {
+		return nil, errors.WithMessage(err, "failed opening cache")
+	}
+	defer cache.Release()
+
 	for _, remotePath := range remotePaths {
 		saFound := false
 
@@ -216,13 +278,19 @@ func (m *ServiceAccountManager) GetServiceAccount(remotePaths ...string) ([]*Rem
 		}
 
 		// find unbanned service account
-		for _, sa := range remote.ServiceAccounts {
+		for _, sa := range m.strategy(remoteName).Order(remoteName, remote.ServiceAccounts) {
 			// does the cache already contain this service account?
 			if exists, _ := cache.IsBanned(sa.RealPath); exists {
 				// service account is currently banned
 				continue
 			}
 
+			// is this service account close to its daily quota?
+			if nearQuota(sa.RealPath) {
+				m.log.Tracef("Skipping service account near its daily quota: %v", sa.RealPath)
+				continue
+			}
+
 			// has this service account been issued within N seconds?
 			if _, err := mcache.Get(sa.RealPath); err == nil {
 				// this sa was in our memory cache and has not expired yet
@@ -231,10 +299,16 @@ func (m *ServiceAccountManager) GetServiceAccount(remotePaths ...string) ([]*Rem
 
 			// this service account is unbanned
 			serviceAccounts = append(serviceAccounts, &RemoteServiceAccount{
+				RemoteName:         remoteName,
 				RemoteEnvVar:       remote.RemoteEnvVar,
 				ServiceAccountPath: sa.RealPath,
 			})
 
+			// remember this service account was issued
+			if err := cache.SetSelected(remoteName, sa.RealPath); err != nil {
+				m.log.WithError(err).Errorf("Failed recording selected service account: %q", sa.RealPath)
+			}
+
 			saFound = true
 			break
 		}
@@ -247,6 +321,14 @@ func (m *ServiceAccountManager) GetServiceAccount(remotePaths ...string) ([]*Rem
 
 		// if we are here, no more service accounts were available
 		m.log.Warnf("No more service accounts available for remote: %q", remoteName)
+		notify.Send(&notify.Notification{
+			Event:   notify.EventServiceAccountsExhausted,
+			Message: fmt.Sprintf("No more service accounts available for remote: %q", remoteName),
+			Fields: map[string]interface{}{
+				"remote": remoteName,
+			},
+		})
+
 		err = fmt.Errorf("failed finding available service account for remote: %q", remoteName)
 		break
 	}
@@ -277,6 +359,83 @@ func (m *ServiceAccountManager) ServiceAccountsCount() int {
 	return n
 }
 
+func (m *ServiceAccountManager) Capacity() ([]RemoteCapacity, error) {
+	// hold the cache open while checking service accounts
+	if err := cache.Acquire(); err != nil {
+		return nil, errors.WithMessage(err, "failed opening cache")
+	}
+	defer cache.Release()
+
+	capacity := make([]RemoteCapacity, 0, len(m.remoteServiceAccounts))
+
+	for remoteName, remote := range m.remoteServiceAccounts {
+		c := RemoteCapacity{
+			Remote:          remoteName,
+			ServiceAccounts: len(remote.ServiceAccounts),
+		}
+
+		for _, sa := range remote.ServiceAccounts {
+			c.Used += ServiceAccountUsage(sa.RealPath)
+			banned, _ := cache.IsBanned(sa.RealPath)
+
+			switch {
+			case banned:
+				c.Banned++
+			case nearQuota(sa.RealPath):
+				c.Exhausted++
+			default:
+				c.Available++
+				c.Remaining += ServiceAccountRemaining(sa.RealPath)
+			}
+		}
+
+		capacity = append(capacity, c)
+	}
+
+	sort.Slice(capacity, func(i, j int) bool {
+		return capacity[i].Remote < capacity[j].Remote
+	})
+
+	return capacity, nil
+}
+
+func (m *ServiceAccountManager) validateServiceAccounts(folder string, serviceAccountFiles []pathutils.Path) []pathutils.Path {
+	valid := make([]pathutils.Path, 0, len(serviceAccountFiles))
+
+	for _, sa := range serviceAccountFiles {
+		email, err := validateServiceAccountFile(sa.RealPath)
+		if err == nil {
+			// is this service account a duplicate of another file?
+			if existing, ok := m.clientEmails[email]; ok && existing != sa.RealPath {
+				err = fmt.Errorf("duplicate client_email %q of: %v", email, existing)
+			}
+		}
+
+		if err == nil {
+			m.clientEmails[email] = sa.RealPath
+			valid = append(valid, sa)
+			continue
+		}
+
+		// quarantine invalid service account
+		m.log.WithError(err).Errorf("Quarantining invalid service account: %v", sa.RealPath)
+
+		if err := quarantineServiceAccountFile(folder, sa.RealPath); err != nil {
+			m.log.WithError(err).Errorf("Failed quarantining invalid service account: %v", sa.RealPath)
+		}
+	}
+
+	return valid
+}
+
+func (m *ServiceAccountManager) strategy(remoteName string) SelectionStrategy {
+	if strategy, ok := m.remoteStrategies[strings.ToLower(remoteName)]; ok {
+		return strategy
+	}
+
+	return sequentialStrategy{}
+}
+
 func RemoveServiceAccountsFromTempCache(serviceAccounts []*RemoteServiceAccount) {
 	mtx.Lock()
 	defer mtx.Unlock()
@@ -301,6 +460,13 @@ func AnyRemotesBanned(remotes []string) (bool, time.Time) {
 		checkRemotes = append(checkRemotes, stringutils.FromLeftUntil(remote, ":"))
 	}
 
+	// check every remote with the cache open once
+	if err := cache.Acquire(); err != nil {
+		log.WithError(err).Error("Failed checking if remotes are banned")
+		return banned, expires
+	}
+	defer cache.Release()
+
 	// iterate remotes
 	for _, remote := range checkRemotes {
 		banned, expires = cache.IsBanned(remote)
@@ -0,0 +1,69 @@
+package rclone
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// invalid service account files are moved into this sub-folder of their service account folder
+	quarantineFolder = "quarantine"
+)
+
+/* Struct */
+
+type serviceAccountFile struct {
+	Type        string `json:"type"`
+	PrivateKey  string `json:"private_key"`
+	ClientEmail string `json:"client_email"`
+}
+
+/* Private */
+
+func validateServiceAccountFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed reading: %w", err)
+	}
+
+	var sa serviceAccountFile
+	if err := json.Unmarshal(b, &sa); err != nil {
+		return "", fmt.Errorf("failed decoding: %w", err)
+	}
+
+	switch {
+	case sa.Type != "service_account":
+		return "", fmt.Errorf("unexpected type: %q", sa.Type)
+	case sa.PrivateKey == "":
+		return "", errors.New("missing private_key")
+	case sa.ClientEmail == "":
+		return "", errors.New("missing client_email")
+	default:
+		break
+	}
+
+	return strings.ToLower(sa.ClientEmail), nil
+}
+
+func quarantineServiceAccountFile(folder string, path string) error {
+	if loaded().cfg.Rclone.DryRun {
+		// leave the file in place
+		return nil
+	}
+
+	dst := filepath.Join(folder, quarantineFolder)
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return fmt.Errorf("failed creating quarantine folder: %w", err)
+	}
+
+	return os.Rename(path, filepath.Join(dst, filepath.Base(path)))
+}
+
+func isQuarantined(folder string, path string) bool {
+	return strings.HasPrefix(path, filepath.Join(folder, quarantineFolder)+string(filepath.Separator))
+}
@@ -0,0 +1,145 @@
+package rclone
+
+import (
+	"fmt"
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+const (
+	StrategySequential = "sequential"
+	StrategyRoundRobin = "round_robin"
+	StrategyLRU        = "lru"
+	StrategyLeastBytes = "least_bytes"
+	StrategyRandom     = "random"
+)
+
+/* Interface */
+
+// SelectionStrategy determines the order service accounts of a remote are tried in.
+type SelectionStrategy interface {
+	Order(remoteName string, serviceAccounts []pathutils.Path) []pathutils.Path
+}
+
+/* Struct */
+
+type sequentialStrategy struct{}
+
+type roundRobinStrategy struct{}
+
+type lruStrategy struct{}
+
+type leastBytesStrategy struct{}
+
+type randomStrategy struct{}
+
+/* Public */
+
+func SupportedStrategies() []string {
+	return []string{StrategySequential, StrategyRoundRobin, StrategyLRU, StrategyLeastBytes, StrategyRandom}
+}
+
+func NewSelectionStrategy(name string) (SelectionStrategy, bool) {
+	switch strings.ToLower(name) {
+	case "", StrategySequential:
+		return sequentialStrategy{}, true
+	case StrategyRoundRobin:
+		return roundRobinStrategy{}, true
+	case StrategyLRU:
+		return lruStrategy{}, true
+	case StrategyLeastBytes:
+		return leastBytesStrategy{}, true
+	case StrategyRandom:
+		return randomStrategy{}, true
+	default:
+		return sequentialStrategy{}, false
+	}
+}
+
+func (sequentialStrategy) Order(_ string, serviceAccounts []pathutils.Path) []pathutils.Path {
+	return serviceAccounts
+}
+
+func (roundRobinStrategy) Order(remoteName string, serviceAccounts []pathutils.Path) []pathutils.Path {
+	// continue after the service account last issued for this remote
+	last := cache.GetSelected(remoteName)
+	if last == "" {
+		return serviceAccounts
+	}
+
+	for i, sa := range serviceAccounts {
+		if sa.RealPath != last {
+			continue
+		}
+
+		ordered := make([]pathutils.Path, 0, len(serviceAccounts))
+		ordered = append(ordered, serviceAccounts[i+1:]...)
+		return append(ordered, serviceAccounts[:i+1]...)
+	}
+
+	return serviceAccounts
+}
+
+func (lruStrategy) Order(_ string, serviceAccounts []pathutils.Path) []pathutils.Path {
+	lastUsed := make(map[string]int64, len(serviceAccounts))
+	for _, sa := range serviceAccounts {
+		lastUsed[sa.RealPath] = cache.GetLastUsed(sa.RealPath).UnixNano()
+	}
+
+	return sortedCopy(serviceAccounts, func(a, b pathutils.Path) bool {
+		return lastUsed[a.RealPath] < lastUsed[b.RealPath]
+	})
+}
+
+func (leastBytesStrategy) Order(_ string, serviceAccounts []pathutils.Path) []pathutils.Path {
+	used := make(map[string]uint64, len(serviceAccounts))
+	for _, sa := range serviceAccounts {
+		used[sa.RealPath] = ServiceAccountUsage(sa.RealPath)
+	}
+
+	return sortedCopy(serviceAccounts, func(a, b pathutils.Path) bool {
+		return used[a.RealPath] < used[b.RealPath]
+	})
+}
+
+func (randomStrategy) Order(_ string, serviceAccounts []pathutils.Path) []pathutils.Path {
+	ordered := make([]pathutils.Path, len(serviceAccounts))
+	copy(ordered, serviceAccounts)
+
+	rand.Shuffle(len(ordered), func(i, j int) {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	})
+
+	return ordered
+}
+
+/* Private */
+
+func validateStrategies(serviceAccountRemotes map[string][]config.ServiceAccountRemote) error {
+	for _, remotes := range serviceAccountRemotes {
+		for _, remote := range remotes {
+			if _, ok := NewSelectionStrategy(remote.Strategy); !ok {
+				return fmt.Errorf("unknown service account strategy for remote %q: %q (supported: %s)",
+					remote.Remote, remote.Strategy, strings.Join(SupportedStrategies(), ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+func sortedCopy(serviceAccounts []pathutils.Path, less func(a, b pathutils.Path) bool) []pathutils.Path {
+	ordered := make([]pathutils.Path, len(serviceAccounts))
+	copy(ordered, serviceAccounts)
+
+	// stable so that ties keep the sequential order
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return less(ordered[i], ordered[j])
+	})
+
+	return ordered
+}
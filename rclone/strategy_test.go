@@ -0,0 +1,128 @@
+package rclone
+
+import (
+	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNewSelectionStrategy(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   SelectionStrategy
+		wantOk bool
+	}{
+		{"", sequentialStrategy{}, true},
+		{"sequential", sequentialStrategy{}, true},
+		{"round_robin", roundRobinStrategy{}, true},
+		{"LRU", lruStrategy{}, true},
+		{"least_bytes", leastBytesStrategy{}, true},
+		{"random", randomStrategy{}, true},
+		{"fastest", sequentialStrategy{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewSelectionStrategy(tt.name)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("NewSelectionStrategy(%q) = %T, %v, want %T, %v", tt.name, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestValidateStrategies(t *testing.T) {
+	valid := map[string][]config.ServiceAccountRemote{
+		"/opt/sa": {{Remote: "tv"}, {Remote: "movies", Strategy: "Round_Robin"}},
+	}
+	if err := validateStrategies(valid); err != nil {
+		t.Errorf("validateStrategies() error = %v", err)
+	}
+
+	invalid := map[string][]config.ServiceAccountRemote{
+		"/opt/sa": {{Remote: "tv", Strategy: "fastest"}},
+	}
+	if err := validateStrategies(invalid); err == nil {
+		t.Error("validateStrategies() error = nil, want unknown strategy")
+	}
+}
+
+func TestSelectionStrategyOrder(t *testing.T) {
+	initTestState(t, config.RcloneConfig{})
+
+	serviceAccounts := testServiceAccounts("a", "b", "c")
+
+	// b is used the most today
+	day := QuotaDay(time.Now())
+	for path, bytes := range map[string]int64{"a": 10, "b": 50} {
+		if err := cache.AddUsage(path, day, bytes); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// c was issued before a, b was never issued
+	for _, path := range []string{"c", "a"} {
+		if err := cache.SetSelected("tv", path); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		name     string
+		strategy SelectionStrategy
+		remote   string
+		want     []string
+	}{
+		{"sequential", sequentialStrategy{}, "tv", []string{"a", "b", "c"}},
+		{"round robin continues after the last issued", roundRobinStrategy{}, "tv", []string{"b", "c", "a"}},
+		{"round robin without a previous selection", roundRobinStrategy{}, "movies", []string{"a", "b", "c"}},
+		{"least recently used", lruStrategy{}, "tv", []string{"b", "c", "a"}},
+		{"least bytes used", leastBytesStrategy{}, "tv", []string{"c", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := paths(tt.strategy.Order(tt.remote, serviceAccounts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Order() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("random", func(t *testing.T) {
+		got := paths(randomStrategy{}.Order("tv", serviceAccounts))
+		sort.Strings(got)
+
+		if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Order() = %v, want a shuffle of %v", got, want)
+		}
+	})
+
+	// the given order is left untouched
+	if got := paths(serviceAccounts); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("service accounts = %v, want them unchanged", got)
+	}
+}
+
+func testServiceAccounts(names ...string) []pathutils.Path {
+	serviceAccounts := make([]pathutils.Path, 0, len(names))
+	for _, name := range names {
+		serviceAccounts = append(serviceAccounts, pathutils.Path{RealPath: name})
+	}
+
+	return serviceAccounts
+}
+
+func paths(serviceAccounts []pathutils.Path) []string {
+	names := make([]string, 0, len(serviceAccounts))
+	for _, sa := range serviceAccounts {
+		names = append(names, sa.RealPath)
+	}
+
+	return names
+}
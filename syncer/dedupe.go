@@ -1,13 +1,14 @@
 package syncer
 
 import (
+	"context"
 	"fmt"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
-func (s *Syncer) Dedupe(additionalRcloneParams []string) error {
+func (s *Syncer) Dedupe(ctx context.Context, additionalRcloneParams []string) error {
 	extraParams := s.Config.RcloneParams.Dedupe
 	if additionalRcloneParams != nil {
 		extraParams = append(extraParams, additionalRcloneParams...)
@@ -34,18 +35,19 @@ func (s *Syncer) Dedupe(additionalRcloneParams []string) error {
 
 		// dedupe remote
 		rLog.Info("Deduping...")
-		success, exitCode, err := rclone.Dedupe(dedupeRemote, extraParams)
+		result, err := rclone.Dedupe(ctx, dedupeRemote, extraParams)
+		s.Stats.Add(result.Stats)
 
 		// check result
 		if err != nil {
 			rLog.WithError(err).Errorf("Failed unexpectedly...")
-			return errors.WithMessagef(err, "dedupe failed unexpectedly with exit code: %v", exitCode)
-		} else if success {
+			return errors.WithMessagef(err, "dedupe failed unexpectedly with exit code: %v", result.ExitCode)
+		} else if result.Success {
 			// successful exit code
 			continue
 		}
 
-		return fmt.Errorf("dedupe failed with exit code: %v", exitCode)
+		return fmt.Errorf("dedupe failed with exit code: %v", result.ExitCode)
 	}
 
 	return nil
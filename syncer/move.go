@@ -1,13 +1,14 @@
 package syncer
 
 import (
+	"context"
 	"fmt"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
-func (s *Syncer) Move(additionalRcloneParams []string) error {
+func (s *Syncer) Move(ctx context.Context, additionalRcloneParams []string) error {
 	moveRemotes := make([]rclone.RemoteInstruction, 0)
 
 	// set variables
@@ -40,18 +41,19 @@ func (s *Syncer) Move(additionalRcloneParams []string) error {
 
 		// move to remote
 		rLog.Info("Moving...")
-		success, exitCode, err := rclone.Move(move.From, move.To, nil, true, extraParams)
+		result, err := rclone.Move(ctx, move.From, move.To, nil, true, extraParams)
+		s.Stats.Add(result.Stats)
 
 		// check result
 		if err != nil {
 			rLog.WithError(err).Errorf("Failed unexpectedly...")
-			return errors.WithMessagef(err, "move failed unexpectedly with exit code: %v", exitCode)
-		} else if success {
+			return errors.WithMessagef(err, "move failed unexpectedly with exit code: %v", result.ExitCode)
+		} else if result.Success {
 			// successful exit code
 			continue
 		}
 
-		return fmt.Errorf("move failed with exit code: %v", exitCode)
+		return fmt.Errorf("move failed with exit code: %v", result.ExitCode)
 	}
 
 	return nil
@@ -1,8 +1,8 @@
 package syncer
 
 import (
+	"context"
 	"fmt"
-	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/l3uddz/crop/stringutils"
 	"github.com/pkg/errors"
@@ -10,7 +10,7 @@ import (
 	"time"
 )
 
-func (s *Syncer) Sync(additionalRcloneParams []string, daisyChain bool) error {
+func (s *Syncer) Sync(ctx context.Context, additionalRcloneParams []string, daisyChain bool) error {
 	// set variables
 	extraParams := s.Config.RcloneParams.Sync
 	if additionalRcloneParams != nil {
@@ -31,6 +31,7 @@ func (s *Syncer) Sync(additionalRcloneParams []string, daisyChain bool) error {
 	for _, remotePath := range s.Config.Remotes.Sync {
 		// set variables
 		attempts := 1
+		backoffs := 0
 
 		// daisy
 		if daisyChain && pos > 0 {
@@ -64,13 +65,14 @@ func (s *Syncer) Sync(additionalRcloneParams []string, daisyChain bool) error {
 
 			// sync
 			rLog.Info("Syncing...")
-			success, exitCode, err := rclone.Sync(srcRemote, remotePath, serviceAccounts, extraParams)
+			result, err := rclone.Sync(ctx, srcRemote, remotePath, serviceAccounts, extraParams)
+			s.Stats.Add(result.Stats)
 
 			// check result
 			if err != nil {
 				rLog.WithError(err).Errorf("Failed unexpectedly...")
-				return errors.WithMessagef(err, "sync failed unexpectedly with exit code: %v", exitCode)
-			} else if success {
+				return errors.WithMessagef(err, "sync failed unexpectedly with exit code: %v", result.ExitCode)
+			} else if result.Success {
 				// successful exit code
 				if !s.Ws.Running {
 					// web service is not running (no live rotate)
@@ -79,40 +81,76 @@ func (s *Syncer) Sync(additionalRcloneParams []string, daisyChain bool) error {
 				break
 			}
 
-			// is this an exit code we can retry?
-			switch exitCode {
-			case rclone.ExitFatalError:
+			// determine why the sync failed
+			rLog = rLog.WithFields(logrus.Fields{
+				"exit_code": result.ExitCode,
+				"failure":   result.Failure,
+			})
+
+			// is this a failure we can recover from?
+			switch result.Failure.Action() {
+			case rclone.ActionRotate:
 				// are we using service accounts?
 				if len(serviceAccounts) == 0 {
 					// we are not using service accounts, so mark this remote as banned
-					if err := cache.SetBanned(stringutils.FromLeftUntil(remotePath, ":"), 25); err != nil {
+					if err := rclone.BanRemote(stringutils.FromLeftUntil(remotePath, ":")); err != nil {
 						rLog.WithError(err).Errorf("Failed banning remote")
 					}
 
-					return fmt.Errorf("sync failed with exit code: %v", exitCode)
+					return fmt.Errorf("sync failed with exit code: %v (%v)", result.ExitCode, result.Failure)
 				}
 
 				// ban this service account
 				for _, sa := range serviceAccounts {
-					if err := cache.SetBanned(sa.ServiceAccountPath, 25); err != nil {
+					if err := rclone.BanServiceAccount(sa.ServiceAccountPath); err != nil {
 						rLog.WithError(err).Error("Failed banning service account, cannot try again...")
 						return fmt.Errorf("failed banning service account: %v", sa.ServiceAccountPath)
 					}
 				}
 
 				// attempt sync again
-				rLog.Warnf("Sync failed with retryable exit code %v, trying again...", exitCode)
+				rLog.Warnf("Sync failed with retryable failure %v, trying again...", result.Failure)
 				attempts++
 				continue
+			case rclone.ActionBackoff:
+				if backoffs >= rclone.MaxBackoffAttempts {
+					return fmt.Errorf("sync failed with exit code: %v (%v) after %d backoff(s)", result.ExitCode,
+						result.Failure, backoffs)
+				}
+
+				// wait before attempting sync again
+				wait := rclone.BackoffDuration(backoffs)
+				rLog.Warnf("Sync failed with retryable failure %v, trying again in %v...", result.Failure, wait)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+
+				backoffs++
+				attempts++
+				continue
+			case rclone.ActionBanRemote:
+				// this remote cannot be used until its quota resets
+				if err := rclone.BanRemote(stringutils.FromLeftUntil(remotePath, ":")); err != nil {
+					rLog.WithError(err).Errorf("Failed banning remote")
+				}
+
+				return fmt.Errorf("sync failed with exit code: %v (%v)", result.ExitCode, result.Failure)
 			default:
-				return fmt.Errorf("failed and cannot proceed with exit code: %v", exitCode)
+				return fmt.Errorf("failed and cannot proceed with exit code: %v (%v)", result.ExitCode,
+					result.Failure)
 			}
 		}
 
 		// sleep before moving on
 		if daisyChain && pos < len(s.Config.Remotes.Sync) {
 			s.Log.Info("Waiting 60 seconds before continuing...")
-			time.Sleep(60 * time.Second)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(60 * time.Second):
+			}
 		}
 	}
 
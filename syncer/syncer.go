@@ -16,6 +16,7 @@ type Syncer struct {
 	Config                    *config.SyncerConfig
 	Name                      string
 	RemoteServiceAccountFiles *rclone.ServiceAccountManager
+	Stats                     rclone.Stats
 	Ws                        *web.Server
 }
 
@@ -0,0 +1,248 @@
+package timeutils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* Const */
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+/* Struct */
+
+// Windows are the minutes of the week that fall within one or more windows.
+type Windows struct {
+	open [minutesPerWeek]bool
+}
+
+// Transition is a minute of the week at which the windows open or close.
+type Transition struct {
+	Minute int
+	Open   bool
+}
+
+/* Var */
+
+var (
+	weekdays = map[string]time.Weekday{
+		"sun": time.Sunday,
+		"mon": time.Monday,
+		"tue": time.Tuesday,
+		"wed": time.Wednesday,
+		"thu": time.Thursday,
+		"fri": time.Friday,
+		"sat": time.Saturday,
+	}
+
+	dayAliases = map[string][]time.Weekday{
+		"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		"weekends": {time.Saturday, time.Sunday},
+	}
+)
+
+/* Public */
+
+// ParseWindows parses windows such as "01:00-07:00 Mon-Fri", "22:00-02:00" (every day, ending the next day)
+// or "Sat,Sun" (all day).
+func ParseWindows(specs []string) (*Windows, error) {
+	w := &Windows{}
+
+	for _, spec := range specs {
+		if err := w.add(spec); err != nil {
+			return nil, fmt.Errorf("invalid window %q: %w", spec, err)
+		}
+	}
+
+	return w, nil
+}
+
+// Contains returns whether t falls within a window, t must be in the location the windows are in.
+func (w *Windows) Contains(t time.Time) bool {
+	return w.open[minuteOfWeek(t)]
+}
+
+// NextOpen returns when the windows next open after t, false if they never do.
+func (w *Windows) NextOpen(t time.Time) (time.Time, bool) {
+	start := minuteOfWeek(t)
+	base := t.Truncate(time.Minute)
+
+	for i := 1; i <= minutesPerWeek; i++ {
+		if w.open[(start+i)%minutesPerWeek] {
+			return base.Add(time.Duration(i) * time.Minute), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Transitions returns the minutes of the week at which the windows open or close, in order.
+func (w *Windows) Transitions() []Transition {
+	transitions := make([]Transition, 0)
+
+	for m := 0; m < minutesPerWeek; m++ {
+		prev := w.open[(m+minutesPerWeek-1)%minutesPerWeek]
+		if w.open[m] != prev {
+			transitions = append(transitions, Transition{Minute: m, Open: w.open[m]})
+		}
+	}
+
+	return transitions
+}
+
+/* Private */
+
+func (w *Windows) add(spec string) error {
+	start, end := 0, minutesPerDay
+	var days []time.Weekday
+
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty window")
+	}
+
+	for _, field := range fields {
+		var err error
+
+		switch {
+		case strings.Contains(field, ":"):
+			start, end, err = parseTimeRange(field)
+		default:
+			var d []time.Weekday
+			d, err = parseDays(field)
+			days = append(days, d...)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// every day
+	if len(days) == 0 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			days = append(days, d)
+		}
+	}
+
+	// windows ending before they start end the next day
+	length := end - start
+	if length <= 0 {
+		length += minutesPerDay
+	}
+
+	for _, d := range days {
+		from := int(d)*minutesPerDay + start
+		for m := 0; m < length; m++ {
+			w.open[(from+m)%minutesPerWeek] = true
+		}
+	}
+
+	return nil
+}
+
+func parseTimeRange(value string) (int, int, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid time range %q, expected HH:MM-HH:MM", value)
+	}
+
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if start == end%minutesPerDay && end != minutesPerDay {
+		return 0, 0, fmt.Errorf("time range %q is empty", value)
+	}
+
+	return start, end, nil
+}
+
+func parseClock(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	switch {
+	case h == 24 && m == 0:
+		return minutesPerDay, nil
+	case h < 0 || h > 23 || m < 0 || m > 59:
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	return h*60 + m, nil
+}
+
+func parseDays(value string) ([]time.Weekday, error) {
+	days := make([]time.Weekday, 0)
+
+	for _, part := range strings.Split(strings.ToLower(value), ",") {
+		if alias, ok := dayAliases[part]; ok {
+			days = append(days, alias...)
+			continue
+		}
+
+		bounds := strings.Split(part, "-")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid days %q", value)
+		}
+
+		from, err := parseDay(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+
+		to := from
+		if len(bounds) == 2 {
+			if to, err = parseDay(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+
+		// ranges may wrap around the week, e.g. Fri-Mon
+		for d := from; ; d = (d + 1) % 7 {
+			days = append(days, d)
+			if d == to {
+				break
+			}
+		}
+	}
+
+	return days, nil
+}
+
+func parseDay(value string) (time.Weekday, error) {
+	if len(value) >= 3 {
+		if d, ok := weekdays[value[:3]]; ok && strings.HasPrefix(strings.ToLower(d.String()), value) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid day %q", value)
+}
+
+func minuteOfWeek(t time.Time) int {
+	return int(t.Weekday())*minutesPerDay + t.Hour()*60 + t.Minute()
+}
@@ -0,0 +1,182 @@
+package timeutils
+
+import (
+	"testing"
+	"time"
+)
+
+// 2021-12-06 is a monday
+func at(day int, hour int, minute int) time.Time {
+	return time.Date(2021, 12, 6+day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		wantErr bool
+	}{
+		{"time range", []string{"01:00-07:00"}, false},
+		{"days", []string{"Sat,Sun"}, false},
+		{"time range & days", []string{"01:00-07:00 Mon-Fri"}, false},
+		{"full day names", []string{"monday-friday"}, false},
+		{"aliases", []string{"22:00-02:00 weekdays", "weekends"}, false},
+		{"until midnight", []string{"18:00-24:00"}, false},
+		{"no windows", nil, false},
+		{"empty", []string{" "}, true},
+		{"missing end", []string{"01:00"}, true},
+		{"invalid time", []string{"25:00-02:00"}, true},
+		{"invalid minute", []string{"01:60-02:00"}, true},
+		{"not a time", []string{"1am-2am"}, true},
+		{"empty range", []string{"02:00-02:00"}, true},
+		{"invalid day", []string{"Mo"}, true},
+		{"unknown day", []string{"Funday"}, true},
+		{"invalid day range", []string{"Mon-Tue-Wed"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWindows(tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseWindows(%q) error = %v, wantErr %v", tt.specs, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWindowsContains(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []string
+		t     time.Time
+		want  bool
+	}{
+		{"within", []string{"01:00-07:00"}, at(2, 3, 0), true},
+		{"start is within", []string{"01:00-07:00"}, at(2, 1, 0), true},
+		{"end is not within", []string{"01:00-07:00"}, at(2, 7, 0), false},
+		{"before", []string{"01:00-07:00"}, at(2, 0, 59), false},
+		{"crossing midnight, before", []string{"22:00-02:00"}, at(0, 23, 30), true},
+		{"crossing midnight, after", []string{"22:00-02:00"}, at(1, 1, 30), true},
+		{"crossing midnight, outside", []string{"22:00-02:00"}, at(1, 12, 0), false},
+		{"crossing midnight into the next day", []string{"22:00-02:00 Fri"}, at(5, 1, 0), true},
+		{"crossing midnight from the previous day", []string{"22:00-02:00 Fri"}, at(4, 1, 0), false},
+		{"crossing the end of the week", []string{"22:00-02:00 Sat"}, at(-1, 1, 0), true},
+		{"until midnight", []string{"18:00-24:00"}, at(0, 23, 59), true},
+		{"until midnight, next day", []string{"18:00-24:00 Mon"}, at(1, 0, 0), false},
+		{"day", []string{"Sat,Sun"}, at(5, 12, 0), true},
+		{"other day", []string{"Sat,Sun"}, at(4, 12, 0), false},
+		{"day range", []string{"01:00-07:00 Mon-Fri"}, at(2, 3, 0), true},
+		{"outside day range", []string{"01:00-07:00 Mon-Fri"}, at(5, 3, 0), false},
+		{"day range wrapping the week", []string{"Fri-Mon"}, at(6, 12, 0), true},
+		{"outside day range wrapping the week", []string{"Fri-Mon"}, at(2, 12, 0), false},
+		{"weekdays", []string{"weekdays"}, at(4, 12, 0), true},
+		{"weekends", []string{"weekends"}, at(4, 12, 0), false},
+		{"multiple windows", []string{"01:00-02:00", "Sun"}, at(6, 12, 0), true},
+		{"no windows", nil, at(0, 12, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := ParseWindows(tt.specs)
+			if err != nil {
+				t.Fatalf("ParseWindows(%q) error = %v", tt.specs, err)
+			}
+
+			if got := w.Contains(tt.t); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowsContainsTimezone(t *testing.T) {
+	w, err := ParseWindows([]string{"01:00-07:00 Mon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// monday 03:00 in tokyo is sunday 18:00 utc
+	tokyo := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2021, 12, 5, 18, 0, 0, 0, time.UTC)
+
+	if w.Contains(now) {
+		t.Errorf("Contains(%v) = true, want false", now)
+	}
+
+	if !w.Contains(now.In(tokyo)) {
+		t.Errorf("Contains(%v) = false, want true", now.In(tokyo))
+	}
+}
+
+func TestWindowsNextOpen(t *testing.T) {
+	tests := []struct {
+		name   string
+		specs  []string
+		t      time.Time
+		want   time.Time
+		wantOk bool
+	}{
+		{"later today", []string{"22:00-02:00"}, at(0, 12, 30), at(0, 22, 0), true},
+		{"tomorrow", []string{"01:00-07:00"}, at(0, 12, 0), at(1, 1, 0), true},
+		{"next week", []string{"01:00-07:00 Mon"}, at(0, 12, 0), at(7, 1, 0), true},
+		{"seconds are dropped", []string{"Tue"}, at(0, 12, 0).Add(30 * time.Second), at(1, 0, 0), true},
+		{"never", nil, at(0, 12, 0), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := ParseWindows(tt.specs)
+			if err != nil {
+				t.Fatalf("ParseWindows(%q) error = %v", tt.specs, err)
+			}
+
+			got, ok := w.NextOpen(tt.t)
+			if ok != tt.wantOk || !got.Equal(tt.want) {
+				t.Errorf("NextOpen(%v) = %v, %v, want %v, %v", tt.t, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestWindowsTransitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []string
+		want  []Transition
+	}{
+		{"never open", nil, []Transition{}},
+		{"always open", []string{"00:00-24:00"}, []Transition{}},
+		{"one day", []string{"Mon"}, []Transition{
+			{Minute: minutesPerDay, Open: true},
+			{Minute: 2 * minutesPerDay, Open: false},
+		}},
+		{"crossing the end of the week", []string{"22:00-02:00 Sat"}, []Transition{
+			{Minute: 2 * 60, Open: false},
+			{Minute: 6*minutesPerDay + 22*60, Open: true},
+		}},
+		{"adjacent windows are merged", []string{"01:00-02:00 Mon", "02:00-03:00 Mon"}, []Transition{
+			{Minute: minutesPerDay + 60, Open: true},
+			{Minute: minutesPerDay + 3*60, Open: false},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := ParseWindows(tt.specs)
+			if err != nil {
+				t.Fatalf("ParseWindows(%q) error = %v", tt.specs, err)
+			}
+
+			got := w.Transitions()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Transitions() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Transitions()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
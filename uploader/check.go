@@ -1,13 +1,29 @@
 package uploader
 
 import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
 	"github.com/l3uddz/crop/uploader/checker"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+)
+
+const (
+	compositeCheckType = "composite"
+	freeSpaceCheckType = "free_space"
 )
 
 var (
 	supportedCheckers = map[string]interface{}{
-		"size": checker.Size{},
-		"age":  checker.Age{},
+		"size":             checker.Size{},
+		"age":              checker.Age{},
+		"count":            checker.Count{},
+		"filesize":         checker.FileSize{},
+		"schedule":         checker.Schedule{},
+		freeSpaceCheckType: checker.FreeSpace{},
 	}
 )
 
@@ -16,7 +32,153 @@ func (u *Uploader) Check() (*checker.Result, error) {
 	return u.Checker.Check(&u.Config.Check, u.Log, u.LocalFiles, u.LocalFilesSize)
 }
 
-func (u *Uploader) CheckRcloneParams() []string {
-	// Return rclone parameters for a passed check
-	return u.Checker.RcloneParams(&u.Config.Check, u.Log)
+func (u *Uploader) CheckRcloneParams(res *checker.Result) checker.RcloneParams {
+	// Return rclone parameters for a passed check, res is nil when the check was skipped
+	return u.Checker.RcloneParams(&u.Config.Check, u.Log, res)
+}
+
+// EligibleFiles returns the local files accepted by the checker, res is nil when the check was skipped.
+func (u *Uploader) EligibleFiles(res *checker.Result) ([]pathutils.Path, error) {
+	files := make([]pathutils.Path, 0)
+	var size uint64
+
+	for _, path := range u.LocalFiles {
+		if path.IsDir {
+			continue
+		}
+
+		ok, err := u.Checker.CheckFile(&u.Config.Check, u.Log, res, path, u.LocalFilesSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed checking %q: %w", path.RealPath, err)
+		}
+
+		if !ok {
+			u.Log.WithField("file_path", path.RelativeRealPath).Trace("File is not eligible for upload")
+			continue
+		}
+
+		files = append(files, path)
+		size += uint64(path.Size)
+	}
+
+	u.Log.WithFields(logrus.Fields{
+		"eligible_files": len(files),
+		"files_size":     humanize.IBytes(size),
+	}).Info("Local files eligible for upload")
+
+	return files, nil
+}
+
+// FilesFromRcloneParams writes the eligible files to a temporary --files-from list, which is removed by the
+// returned func. No params are returned when there are no eligible files.
+func (u *Uploader) FilesFromRcloneParams(res *checker.Result) ([]string, func(), error) {
+	files, err := u.EligibleFiles(res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, func() {}, nil
+	}
+
+	// paths are relative to the local folder
+	lines := make([]string, 0, len(files))
+	for _, path := range files {
+		lines = append(lines, path.RelativeRealPath)
+	}
+
+	name, cleanup, err := u.writeTempList("crop_files_from_*.txt", lines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []string{"--files-from-raw", name}, cleanup, nil
+}
+
+func SupportedCheckTypes() []string {
+	types := make([]string, 0, len(supportedCheckers)+1)
+	for t := range supportedCheckers {
+		types = append(types, t)
+	}
+
+	// composite checks are built for each uploader from their rules
+	types = append(types, compositeCheckType)
+
+	sort.Strings(types)
+	return types
+}
+
+func SupportedCheckOperators() []string {
+	return checker.SupportedOperators()
+}
+
+func SupportedCheckDirections() []string {
+	return checker.SupportedDirections()
+}
+
+func newChecker(cfg *config.UploaderConfig) (checker.Interface, error) {
+	var chk checker.Interface
+	var err error
+
+	if strings.EqualFold(cfg.Check.Type, compositeCheckType) {
+		chk, err = newCompositeChecker(cfg.Check.Operator, cfg.Check.Rules, cfg.LocalFolder)
+	} else {
+		chk, err = lookupChecker(strings.ToLower(cfg.Check.Type), cfg.LocalFolder)
+	}
+
+	if err != nil || cfg.Check.MinFreeSpace == 0 {
+		return chk, err
+	}
+
+	// min_free_space is a free_space rule or'd with the check
+	return newCompositeChecker(checker.OperatorOr, []config.UploaderCheckRule{
+		{Type: freeSpaceCheckType, Limit: cfg.Check.MinFreeSpace},
+	}, cfg.LocalFolder, checker.Rule{
+		Checker: chk,
+		Config:  config.UploaderCheck{UploaderCheckRule: cfg.Check.UploaderCheckRule},
+	})
+}
+
+func newCompositeChecker(operator string, rules []config.UploaderCheckRule, localFolder string,
+	extra ...checker.Rule) (checker.Interface, error) {
+	compositeRules := append(make([]checker.Rule, 0, len(rules)+len(extra)), extra...)
+
+	for _, r := range rules {
+		r.Type = strings.ToLower(r.Type)
+		if r.Type == compositeCheckType {
+			return nil, fmt.Errorf("composite checks cannot be nested")
+		}
+
+		chk, err := lookupChecker(r.Type, localFolder)
+		if err != nil {
+			return nil, err
+		}
+
+		compositeRules = append(compositeRules, checker.Rule{
+			Checker: chk,
+			Config:  config.UploaderCheck{UploaderCheckRule: r},
+		})
+	}
+
+	return checker.NewComposite(operator, compositeRules), nil
+}
+
+func lookupChecker(checkType string, localFolder string) (checker.Interface, error) {
+	c, found := supportedCheckers[checkType]
+	if !found {
+		return nil, fmt.Errorf("unknown check type specified: %q", checkType)
+	}
+
+	// free space is checked on the local folder's disk
+	if fs, ok := c.(checker.FreeSpace); ok {
+		fs.Folder = localFolder
+		c = fs
+	}
+
+	chk, ok := c.(checker.Interface)
+	if !ok {
+		return nil, fmt.Errorf("failed typecasting to checker interface for: %q", checkType)
+	}
+
+	return chk, nil
 }
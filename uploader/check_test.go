@@ -0,0 +1,98 @@
+package uploader
+
+import (
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/l3uddz/crop/uploader/checker"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilesFromRcloneParams(t *testing.T) {
+	localFiles := []pathutils.Path{
+		{RealPath: "/mnt/tv/Show", RelativeRealPath: "Show", IsDir: true},
+		{RealPath: "/mnt/tv/Show/small.mkv", RelativeRealPath: "Show/small.mkv", Size: 50},
+		{RealPath: "/mnt/tv/Show/[1080p] large.mkv", RelativeRealPath: "Show/[1080p] large.mkv", Size: 200},
+		{RealPath: "/mnt/tv/huge.mkv", RelativeRealPath: "huge.mkv", Size: 500},
+	}
+
+	tests := []struct {
+		name      string
+		limit     uint64
+		wantLines []string
+	}{
+		{"eligible files only", 100, []string{"Show/[1080p] large.mkv", "huge.mkv"}},
+		{"no eligible files", 1000, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &Uploader{
+				Log: testLog(),
+				Config: &config.UploaderConfig{
+					Check: config.UploaderCheck{
+						UploaderCheckRule: config.UploaderCheckRule{Type: "filesize", Limit: tt.limit},
+						FilesFrom:         true,
+					},
+				},
+				Checker:    checker.FileSize{},
+				LocalFiles: localFiles,
+			}
+
+			res, err := u.Check()
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			params, cleanup, err := u.FilesFromRcloneParams(res)
+			if err != nil {
+				t.Fatalf("FilesFromRcloneParams() error = %v", err)
+			}
+
+			if tt.wantLines == nil {
+				if params != nil {
+					t.Errorf("FilesFromRcloneParams() = %v, want no params", params)
+				}
+				return
+			}
+
+			if len(params) != 2 || params[0] != "--files-from-raw" {
+				cleanup()
+				t.Fatalf("FilesFromRcloneParams() = %v, want --files-from-raw <list>", params)
+			}
+
+			got := readLines(t, params[1])
+			cleanup()
+
+			if !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("list = %q, want %q", got, tt.wantLines)
+			}
+
+			if _, err := os.Stat(params[1]); !os.IsNotExist(err) {
+				t.Errorf("list %q was not removed", params[1])
+			}
+		})
+	}
+}
+
+func readLines(t *testing.T, name string) []string {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func testLog() *logrus.Entry {
+	l := logrus.New()
+	l.SetOutput(ioutil.Discard)
+
+	return logrus.NewEntry(l)
+}
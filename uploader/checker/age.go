@@ -64,7 +64,8 @@ func (Age) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutils
 	}, nil
 }
 
-func (Age) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, path pathutils.Path, size uint64) (bool, error) {
+func (Age) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
 	maxFileAge := time.Now().Add(time.Duration(-cfg.Limit) * time.Minute)
 
 	// Check File Age
@@ -75,7 +76,7 @@ func (Age) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, path pathutil
 	return false, nil
 }
 
-func (Age) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry) []string {
+func (Age) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
 	params := []string{
 		"--min-age",
 		fmt.Sprintf("%dm", cfg.Limit),
@@ -84,5 +85,7 @@ func (Age) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry) []string {
 	// add filters
 	params = append(params, rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude)...)
 
-	return params
+	return RcloneParams{
+		Filters: params,
+	}
 }
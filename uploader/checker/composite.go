@@ -0,0 +1,188 @@
+package checker
+
+import (
+	"fmt"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+/* Const */
+
+const (
+	OperatorAnd = "and"
+	OperatorOr  = "or"
+)
+
+/* Struct */
+
+type Rule struct {
+	Checker Interface
+	Config  config.UploaderCheck
+}
+
+type Composite struct {
+	Operator string
+	Rules    []Rule
+}
+
+/* Public */
+
+func NewComposite(operator string, rules []Rule) *Composite {
+	op := strings.ToLower(operator)
+	if op == "" {
+		op = OperatorOr
+	}
+
+	return &Composite{
+		Operator: op,
+		Rules:    rules,
+	}
+}
+
+func SupportedOperators() []string {
+	return []string{OperatorAnd, OperatorOr}
+}
+
+func (c *Composite) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutils.Path, size uint64) (*Result, error) {
+	results := make([]RuleResult, 0, len(c.Rules))
+	infos := make([]string, 0, len(c.Rules))
+
+	for _, r := range c.Rules {
+		r := r
+
+		rl := log.WithField("rule", r.Config.Type)
+		if r.Config.Limit > 0 {
+			rl = rl.WithField("limit", r.Config.Limit)
+		}
+
+		res, err := r.Checker.Check(&r.Config, rl, paths, size)
+		if err != nil {
+			// a failed rule does not pass, the remaining rules decide
+			rl.WithError(err).Error("Failed checking rule")
+			res = &Result{Passed: false, Info: "unknown"}
+		}
+
+		rl.WithFields(logrus.Fields{
+			"passed": res.Passed,
+			"info":   res.Info,
+		}).Debug("Checked rule")
+
+		results = append(results, RuleResult{
+			Type:   r.Config.Type,
+			Limit:  r.Config.Limit,
+			Result: res,
+		})
+
+		if len(res.Rules) > 0 {
+			// a nested composite check already describes its rules
+			infos = append(infos, fmt.Sprint(res.Info))
+		} else {
+			infos = append(infos, fmt.Sprintf("%s: %v", r.Config.Type, res.Info))
+		}
+	}
+
+	return c.combine(results, strings.Join(infos, ", ")), nil
+}
+
+func (c *Composite) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
+	passed := make([]bool, 0, len(c.Rules))
+
+	for i, r := range c.Rules {
+		r := r
+		rr := ruleResult(res, i)
+
+		if c.Operator == OperatorOr && rr != nil {
+			switch {
+			case !rr.Passed:
+				// the rule did not trigger the upload
+				continue
+			case rr.AllFiles:
+				// the rule accepts every file
+				return true, nil
+			}
+		}
+
+		ok, err := r.Checker.CheckFile(&r.Config, log, rr, path, size)
+		if err != nil {
+			return false, fmt.Errorf("%s rule: %w", r.Config.Type, err)
+		}
+
+		passed = append(passed, ok)
+	}
+
+	return c.evaluate(passed), nil
+}
+
+func (c *Composite) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
+	params := RcloneParams{}
+
+	for i, r := range c.Rules {
+		r := r
+		rr := ruleResult(res, i)
+
+		if c.Operator == OperatorOr && rr != nil && !rr.Passed {
+			// the rule did not trigger the upload
+			continue
+		}
+
+		p := r.Checker.RcloneParams(&r.Config, log, rr)
+		params.Flags = append(params.Flags, p.Flags...)
+
+		if res == nil || !res.AllFiles {
+			params.Filters = append(params.Filters, p.Filters...)
+		}
+	}
+
+	// add filters
+	params.Filters = append(params.Filters, rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude)...)
+
+	return params
+}
+
+/* Private */
+
+func (c *Composite) combine(results []RuleResult, info string) *Result {
+	passed := make([]bool, 0, len(results))
+	allFiles := make([]bool, 0, len(results))
+
+	for _, r := range results {
+		passed = append(passed, r.Passed)
+
+		// with or, only the rules that passed decide which files are uploaded
+		if c.Operator == OperatorAnd || r.Passed {
+			allFiles = append(allFiles, r.AllFiles)
+		}
+	}
+
+	return &Result{
+		Passed:   c.evaluate(passed),
+		Info:     info,
+		AllFiles: c.evaluate(allFiles),
+		Rules:    results,
+	}
+}
+
+func (c *Composite) evaluate(values []bool) bool {
+	for _, v := range values {
+		switch {
+		case c.Operator == OperatorAnd && !v:
+			return false
+		case c.Operator == OperatorOr && v:
+			return true
+		}
+	}
+
+	return c.Operator == OperatorAnd
+}
+
+func ruleResult(res *Result, i int) *Result {
+	if res == nil || i >= len(res.Rules) {
+		return nil
+	}
+
+	return res.Rules[i].Result
+}
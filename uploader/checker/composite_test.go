@@ -0,0 +1,260 @@
+package checker
+
+import (
+	"errors"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+type stubChecker struct {
+	passed   bool
+	allFiles bool
+	fileOK   bool
+	filter   string
+	flag     string
+	err      error
+}
+
+func (s stubChecker) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutils.Path,
+	size uint64) (*Result, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &Result{Passed: s.passed, AllFiles: s.allFiles, Info: s.passed}, nil
+}
+
+func (s stubChecker) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
+	return s.fileOK, nil
+}
+
+func (s stubChecker) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
+	params := RcloneParams{}
+	if s.filter != "" {
+		params.Filters = append(params.Filters, s.filter)
+	}
+	if s.flag != "" {
+		params.Flags = append(params.Flags, s.flag)
+	}
+
+	return params
+}
+
+func TestCompositeCheck(t *testing.T) {
+	var (
+		passAll = stubChecker{passed: true, allFiles: true}
+		pass    = stubChecker{passed: true}
+		failAll = stubChecker{allFiles: true}
+		fail    = stubChecker{}
+		failing = stubChecker{err: errors.New("failed")}
+	)
+
+	tests := []struct {
+		name         string
+		operator     string
+		rules        []Interface
+		wantPassed   bool
+		wantAllFiles bool
+	}{
+		{"or defaults", "", []Interface{fail, pass}, true, false},
+		{"or none passed", OperatorOr, []Interface{fail, failAll}, false, false},
+		{"or one passed", OperatorOr, []Interface{fail, pass}, true, false},
+		{"or passed rule uploads all", OperatorOr, []Interface{pass, passAll}, true, true},
+		{"or failed rule does not upload all", OperatorOr, []Interface{failAll, pass}, true, false},
+		{"or error does not pass", OperatorOr, []Interface{failing, pass}, true, false},
+		{"and all passed", OperatorAnd, []Interface{passAll, pass}, true, false},
+		{"and all passed upload all", OperatorAnd, []Interface{passAll, passAll}, true, true},
+		{"and one failed", OperatorAnd, []Interface{passAll, fail}, false, false},
+		{"and error does not pass", OperatorAnd, []Interface{passAll, failing}, false, false},
+		{"upper case operator", "AND", []Interface{pass, fail}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComposite(tt.operator, stubRules(tt.rules...))
+
+			res, err := c.Check(&config.UploaderCheck{}, testLog(), nil, 0)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if res.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v", res.Passed, tt.wantPassed)
+			}
+
+			if res.AllFiles != tt.wantAllFiles {
+				t.Errorf("AllFiles = %v, want %v", res.AllFiles, tt.wantAllFiles)
+			}
+
+			if len(res.Rules) != len(tt.rules) {
+				t.Errorf("Rules = %d, want %d", len(res.Rules), len(tt.rules))
+			}
+		})
+	}
+}
+
+func TestCompositeCheckFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		rules    []Interface
+		checked  bool
+		want     bool
+	}{
+		{"or accepted by a passed rule", OperatorOr, []Interface{
+			stubChecker{passed: true, fileOK: true},
+			stubChecker{},
+		}, true, true},
+		{"or failed rule is ignored", OperatorOr, []Interface{
+			stubChecker{passed: true},
+			stubChecker{fileOK: true},
+		}, true, false},
+		{"or passed rule uploading all", OperatorOr, []Interface{
+			stubChecker{passed: true, allFiles: true},
+			stubChecker{passed: true},
+		}, true, true},
+		{"or without a result", OperatorOr, []Interface{
+			stubChecker{},
+			stubChecker{fileOK: true},
+		}, false, true},
+		{"and accepted by all", OperatorAnd, []Interface{
+			stubChecker{passed: true, fileOK: true},
+			stubChecker{passed: true, fileOK: true},
+		}, true, true},
+		{"and rejected by one", OperatorAnd, []Interface{
+			stubChecker{passed: true, fileOK: true},
+			stubChecker{passed: true},
+		}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComposite(tt.operator, stubRules(tt.rules...))
+			cfg := &config.UploaderCheck{}
+
+			var res *Result
+			if tt.checked {
+				var err error
+				if res, err = c.Check(cfg, testLog(), nil, 0); err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+			}
+
+			got, err := c.CheckFile(cfg, testLog(), res, pathutils.Path{}, 0)
+			if err != nil {
+				t.Fatalf("CheckFile() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("CheckFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompositeRcloneParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		rules    []Interface
+		checked  bool
+		want     RcloneParams
+	}{
+		{"or only passed rules", OperatorOr, []Interface{
+			stubChecker{passed: true, filter: "--min-age=1h", flag: "--bwlimit=1M"},
+			stubChecker{filter: "--min-size=1G", flag: "--tpslimit=1"},
+		}, true, RcloneParams{Filters: []string{"--min-age=1h"}, Flags: []string{"--bwlimit=1M"}}},
+		{"or uploading all drops filters", OperatorOr, []Interface{
+			stubChecker{passed: true, filter: "--min-age=1h"},
+			stubChecker{passed: true, allFiles: true, flag: "--bwlimit=1M"},
+		}, true, RcloneParams{Flags: []string{"--bwlimit=1M"}}},
+		{"and every rule", OperatorAnd, []Interface{
+			stubChecker{passed: true, filter: "--min-age=1h"},
+			stubChecker{passed: true, filter: "--min-size=1G", flag: "--bwlimit=1M"},
+		}, true, RcloneParams{
+			Filters: []string{"--min-age=1h", "--min-size=1G"},
+			Flags:   []string{"--bwlimit=1M"},
+		}},
+		{"without a result", OperatorOr, []Interface{
+			stubChecker{filter: "--min-age=1h"},
+			stubChecker{flag: "--bwlimit=1M"},
+		}, false, RcloneParams{Filters: []string{"--min-age=1h"}, Flags: []string{"--bwlimit=1M"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComposite(tt.operator, stubRules(tt.rules...))
+			cfg := &config.UploaderCheck{}
+
+			var res *Result
+			if tt.checked {
+				var err error
+				if res, err = c.Check(cfg, testLog(), nil, 0); err != nil {
+					t.Fatalf("Check() error = %v", err)
+				}
+			}
+
+			got := c.RcloneParams(cfg, testLog(), res)
+			if !reflect.DeepEqual(got.Filters, tt.want.Filters) || !reflect.DeepEqual(got.Flags, tt.want.Flags) {
+				t.Errorf("RcloneParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompositeNested(t *testing.T) {
+	inner := NewComposite(OperatorAnd, []Rule{
+		stubRule("age", stubChecker{passed: true}),
+		stubRule("size", stubChecker{passed: true}),
+	})
+
+	outer := NewComposite(OperatorOr, []Rule{
+		stubRule("free_space", stubChecker{}),
+		stubRule("composite", inner),
+	})
+
+	res, err := outer.Check(&config.UploaderCheck{}, testLog(), nil, 0)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if !res.Passed {
+		t.Errorf("Passed = false, want true")
+	}
+
+	if r := res.PassedRule("size"); r == nil || r.Type != "size" {
+		t.Errorf("PassedRule(size) = %v, want the nested size rule", r)
+	}
+
+	if r := res.PassedRule("free_space"); r != nil {
+		t.Errorf("PassedRule(free_space) = %v, want nil", r)
+	}
+}
+
+func stubRules(checkers ...Interface) []Rule {
+	rules := make([]Rule, 0, len(checkers))
+	for _, c := range checkers {
+		rules = append(rules, stubRule("stub", c))
+	}
+
+	return rules
+}
+
+func stubRule(t string, c Interface) Rule {
+	return Rule{
+		Checker: c,
+		Config:  config.UploaderCheck{UploaderCheckRule: config.UploaderCheckRule{Type: t}},
+	}
+}
+
+func testLog() *logrus.Entry {
+	l := logrus.New()
+	l.SetOutput(ioutil.Discard)
+
+	return logrus.NewEntry(l)
+}
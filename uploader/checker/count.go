@@ -0,0 +1,59 @@
+package checker
+
+import (
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/sirupsen/logrus"
+)
+
+type Count struct{}
+
+func (Count) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutils.Path, size uint64) (*Result, error) {
+	// Check File Count
+	count := countFiles(paths)
+
+	if count > cfg.Limit {
+		log.WithFields(logrus.Fields{
+			"max_count":     cfg.Limit,
+			"current_count": count,
+			"over_count":    count - cfg.Limit,
+		}).Info("File count is greater than specified limit")
+
+		return &Result{
+			Passed:   true,
+			AllFiles: true,
+			Info:     count,
+		}, nil
+	}
+
+	return &Result{
+		Passed:   false,
+		AllFiles: true,
+		Info:     cfg.Limit - count,
+	}, nil
+}
+
+func (Count) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
+	// the count applies to all files, so every file is eligible once the check has passed
+	return !path.IsDir, nil
+}
+
+func (Count) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
+	return RcloneParams{
+		Filters: rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude),
+	}
+}
+
+func countFiles(paths []pathutils.Path) uint64 {
+	var count uint64
+
+	for _, path := range paths {
+		if !path.IsDir {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,63 @@
+package checker
+
+import (
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"testing"
+)
+
+func TestCountCheck(t *testing.T) {
+	paths := []pathutils.Path{
+		{Path: "/mnt/tv", IsDir: true},
+		{Path: "/mnt/tv/1.mkv"},
+		{Path: "/mnt/tv/2.mkv"},
+		{Path: "/mnt/tv/3.mkv"},
+	}
+
+	tests := []struct {
+		name       string
+		limit      uint64
+		wantPassed bool
+		wantInfo   uint64
+	}{
+		{"more files than the limit", 2, true, 3},
+		{"as many files as the limit", 3, false, 0},
+		{"fewer files than the limit", 5, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.UploaderCheck{UploaderCheckRule: config.UploaderCheckRule{Type: "count", Limit: tt.limit}}
+
+			res, err := Count{}.Check(cfg, testLog(), paths, 0)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if res.Passed != tt.wantPassed || res.Info != tt.wantInfo {
+				t.Errorf("Check() = %v, %v, want %v, %v", res.Passed, res.Info, tt.wantPassed, tt.wantInfo)
+			}
+
+			if !res.AllFiles {
+				t.Errorf("AllFiles = false, want true")
+			}
+		})
+	}
+}
+
+func TestCountCheckFile(t *testing.T) {
+	cfg := &config.UploaderCheck{UploaderCheckRule: config.UploaderCheckRule{Type: "count", Limit: 1}}
+
+	for _, tt := range []struct {
+		path pathutils.Path
+		want bool
+	}{
+		{pathutils.Path{Path: "/mnt/tv/1.mkv"}, true},
+		{pathutils.Path{Path: "/mnt/tv", IsDir: true}, false},
+	} {
+		got, err := Count{}.CheckFile(cfg, testLog(), nil, tt.path, 0)
+		if err != nil || got != tt.want {
+			t.Errorf("CheckFile(%q) = %v, %v, want %v", tt.path.Path, got, err, tt.want)
+		}
+	}
+}
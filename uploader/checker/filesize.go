@@ -0,0 +1,108 @@
+package checker
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+const (
+	DirectionAbove = "above"
+	DirectionBelow = "below"
+)
+
+type FileSize struct{}
+
+func (FileSize) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutils.Path, size uint64) (*Result, error) {
+	var filesPassed int
+	var filesSize uint64
+
+	for _, path := range paths {
+		path := path
+
+		// skip directories
+		if path.IsDir {
+			continue
+		}
+
+		if !fileSizeMatches(cfg, path) {
+			continue
+		}
+
+		filesPassed++
+		filesSize += uint64(path.Size)
+
+		log.WithFields(logrus.Fields{
+			"limit":     humanize.IBytes(cfg.Limit),
+			"direction": fileSizeDirection(cfg),
+			"file_size": humanize.IBytes(uint64(path.Size)),
+			"file_path": path.Path,
+		}).Trace("File size matches specified limit")
+	}
+
+	if filesPassed == 0 {
+		return &Result{
+			Passed: false,
+			Info:   fmt.Sprintf("no files %s %s", fileSizeDirection(cfg), humanize.IBytes(cfg.Limit)),
+		}, nil
+	}
+
+	log.WithFields(logrus.Fields{
+		"files_passed": filesPassed,
+		"files_size":   humanize.IBytes(filesSize),
+	}).Info("Local files matching check criteria")
+
+	return &Result{
+		Passed: true,
+		Info:   fmt.Sprintf("%d files", filesPassed),
+	}, nil
+}
+
+func (FileSize) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
+	return !path.IsDir && fileSizeMatches(cfg, path), nil
+}
+
+func (FileSize) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
+	flag := "--min-size"
+	if fileSizeDirection(cfg) == DirectionBelow {
+		flag = "--max-size"
+	}
+
+	params := []string{
+		flag,
+		fmt.Sprintf("%dB", cfg.Limit),
+	}
+
+	// add filters
+	params = append(params, rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude)...)
+
+	return RcloneParams{
+		Filters: params,
+	}
+}
+
+func SupportedDirections() []string {
+	return []string{DirectionAbove, DirectionBelow}
+}
+
+func fileSizeDirection(cfg *config.UploaderCheck) string {
+	if strings.EqualFold(cfg.Direction, DirectionBelow) {
+		return DirectionBelow
+	}
+
+	return DirectionAbove
+}
+
+// fileSizeMatches matches rclone's --min-size / --max-size, which include the limit.
+func fileSizeMatches(cfg *config.UploaderCheck, path pathutils.Path) bool {
+	if fileSizeDirection(cfg) == DirectionBelow {
+		return uint64(path.Size) <= cfg.Limit
+	}
+
+	return uint64(path.Size) >= cfg.Limit
+}
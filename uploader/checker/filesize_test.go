@@ -0,0 +1,87 @@
+package checker
+
+import (
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"reflect"
+	"testing"
+)
+
+func TestFileSizeCheck(t *testing.T) {
+	paths := []pathutils.Path{
+		{Path: "/mnt/tv", IsDir: true, Size: 4096},
+		{Path: "/mnt/tv/small.mkv", Size: 50},
+		{Path: "/mnt/tv/exact.mkv", Size: 100},
+	}
+
+	tests := []struct {
+		name       string
+		direction  string
+		limit      uint64
+		wantPassed bool
+		wantFiles  map[string]bool
+	}{
+		{"above includes the limit", "", 100, true,
+			map[string]bool{"/mnt/tv/small.mkv": false, "/mnt/tv/exact.mkv": true, "/mnt/tv": false}},
+		{"none above", DirectionAbove, 1000, false,
+			map[string]bool{"/mnt/tv/small.mkv": false, "/mnt/tv/exact.mkv": false}},
+		{"below includes the limit", DirectionBelow, 50, true,
+			map[string]bool{"/mnt/tv/small.mkv": true, "/mnt/tv/exact.mkv": false, "/mnt/tv": false}},
+		{"none below", "Below", 10, false,
+			map[string]bool{"/mnt/tv/small.mkv": false, "/mnt/tv/exact.mkv": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fileSizeConfig(tt.direction, tt.limit)
+
+			res, err := FileSize{}.Check(cfg, testLog(), paths, 0)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if res.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v", res.Passed, tt.wantPassed)
+			}
+
+			for _, path := range paths {
+				want, ok := tt.wantFiles[path.Path]
+				if !ok {
+					continue
+				}
+
+				got, err := FileSize{}.CheckFile(cfg, testLog(), res, path, 0)
+				if err != nil || got != want {
+					t.Errorf("CheckFile(%q) = %v, %v, want %v", path.Path, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileSizeRcloneParams(t *testing.T) {
+	tests := []struct {
+		direction string
+		want      []string
+	}{
+		{"", []string{"--min-size", "100B"}},
+		{DirectionBelow, []string{"--max-size", "100B"}},
+	}
+
+	for _, tt := range tests {
+		got := FileSize{}.RcloneParams(fileSizeConfig(tt.direction, 100), testLog(), nil)
+		if !reflect.DeepEqual(got.Filters, tt.want) {
+			t.Errorf("RcloneParams(%q).Filters = %q, want %q", tt.direction, got.Filters, tt.want)
+		}
+	}
+}
+
+func fileSizeConfig(direction string, limit uint64) *config.UploaderCheck {
+	return &config.UploaderCheck{
+		UploaderCheckRule: config.UploaderCheckRule{
+			Type:      "filesize",
+			Limit:     limit,
+			Direction: direction,
+		},
+	}
+}
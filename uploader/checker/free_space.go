@@ -0,0 +1,78 @@
+package checker
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/sirupsen/logrus"
+)
+
+type FreeSpace struct {
+	Folder string
+}
+
+func (c FreeSpace) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutils.Path, size uint64) (*Result, error) {
+	free, err := c.free()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check Free Space
+	if free < cfg.Limit {
+		log.WithFields(logrus.Fields{
+			"min_free":   humanize.IBytes(cfg.Limit),
+			"free_disk":  humanize.IBytes(free),
+			"under_free": humanize.IBytes(cfg.Limit - free),
+		}).Info("Free space is below specified limit")
+
+		return &Result{
+			Passed:   true,
+			AllFiles: true,
+			Info:     humanize.IBytes(free),
+		}, nil
+	}
+
+	return &Result{
+		Passed:   false,
+		AllFiles: true,
+		Info:     humanize.IBytes(free - cfg.Limit),
+	}, nil
+}
+
+func (c FreeSpace) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
+	// free space is the same for every file, use the result of the check
+	if res != nil {
+		return res.Passed, nil
+	}
+
+	free, err := c.free()
+	if err != nil {
+		return false, err
+	}
+
+	// Check Free Space
+	if free < cfg.Limit {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (FreeSpace) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
+	return RcloneParams{
+		Filters: rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude),
+	}
+}
+
+func (c FreeSpace) free() (uint64, error) {
+	du, err := disk.Usage(c.Folder)
+	if err != nil {
+		return 0, fmt.Errorf("failed checking available free space for %q: %w", c.Folder, err)
+	}
+
+	return du.Free, nil
+}
@@ -0,0 +1,34 @@
+package checker
+
+import (
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"testing"
+)
+
+func TestFreeSpaceCheckFile(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *Result
+		want bool
+	}{
+		{"below limit", &Result{Passed: true, AllFiles: true}, true},
+		{"above limit", &Result{Passed: false, AllFiles: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the folder does not exist, so the disk is not checked again
+			c := FreeSpace{Folder: "/nonexistent/crop"}
+
+			got, err := c.CheckFile(&config.UploaderCheck{}, testLog(), tt.res, pathutils.Path{}, 0)
+			if err != nil {
+				t.Fatalf("CheckFile() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("CheckFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,15 +4,63 @@ import (
 	"github.com/l3uddz/crop/config"
 	"github.com/l3uddz/crop/pathutils"
 	"github.com/sirupsen/logrus"
+	"strings"
 )
 
+// Interface is implemented by the upload checks. The *Result passed to CheckFile & RcloneParams is the one returned
+// by Check, or nil when the check was skipped.
 type Interface interface {
 	Check(*config.UploaderCheck, *logrus.Entry, []pathutils.Path, uint64) (*Result, error)
-	CheckFile(*config.UploaderCheck, *logrus.Entry, pathutils.Path, uint64) (bool, error)
-	RcloneParams(check *config.UploaderCheck, entry *logrus.Entry) []string
+	CheckFile(*config.UploaderCheck, *logrus.Entry, *Result, pathutils.Path, uint64) (bool, error)
+	RcloneParams(*config.UploaderCheck, *logrus.Entry, *Result) RcloneParams
 }
 
 type Result struct {
 	Passed bool
 	Info   interface{}
+	// every local file is uploaded once the check passed, rather than only those it accepts (e.g. by age)
+	AllFiles bool
+	// results of the rules of a composite check
+	Rules []RuleResult
+}
+
+type RuleResult struct {
+	Type  string
+	Limit uint64
+	*Result
+}
+
+type RcloneParams struct {
+	// Filters limit which files are uploaded, e.g. --min-age
+	Filters []string
+	// Flags are any other params, e.g. --bwlimit
+	Flags []string
+}
+
+// PassedRule returns the first passed rule of type t, including those of nested composite checks, or nil.
+func (r *Result) PassedRule(t string) *RuleResult {
+	return passedRule(r.Rules, t)
+}
+
+// All returns the filters followed by the flags.
+func (p RcloneParams) All() []string {
+	return append(append([]string{}, p.Filters...), p.Flags...)
+}
+
+func passedRule(rules []RuleResult, t string) *RuleResult {
+	for i := range rules {
+		if !rules[i].Passed {
+			continue
+		}
+
+		if strings.EqualFold(rules[i].Type, t) {
+			return &rules[i]
+		}
+
+		if nested := passedRule(rules[i].Rules, t); nested != nil {
+			return nested
+		}
+	}
+
+	return nil
 }
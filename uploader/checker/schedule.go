@@ -0,0 +1,151 @@
+package checker
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/l3uddz/crop/rclone"
+	"github.com/l3uddz/crop/timeutils"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Schedule struct{}
+
+func (Schedule) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutils.Path, size uint64) (*Result, error) {
+	windows, loc, err := scheduleWindows(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check Window
+	now := time.Now().In(loc)
+
+	if windows.Contains(now) {
+		log.WithFields(logrus.Fields{
+			"windows": strings.Join(cfg.Windows, ", "),
+			"now":     now.Format("Mon 15:04 MST"),
+		}).Info("Time is within specified windows")
+
+		return &Result{
+			Passed:   true,
+			AllFiles: true,
+			Info:     now.Format("Mon 15:04 MST"),
+		}, nil
+	}
+
+	next, ok := windows.NextOpen(now)
+	if !ok {
+		return &Result{
+			Passed:   false,
+			AllFiles: true,
+			Info:     "never",
+		}, nil
+	}
+
+	return &Result{
+		Passed:   false,
+		AllFiles: true,
+		Info:     humanize.RelTime(now, next, "", ""),
+	}, nil
+}
+
+func (Schedule) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
+	windows, loc, err := scheduleWindows(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	return windows.Contains(time.Now().In(loc)), nil
+}
+
+func (Schedule) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
+	params := RcloneParams{
+		Filters: rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude),
+	}
+
+	if cfg.BwLimit != "" {
+		timetable, err := scheduleTimetable(cfg)
+		switch {
+		case err != nil:
+			log.WithError(err).Error("Failed building bwlimit timetable")
+		case timetable != "":
+			params.Flags = append(params.Flags, "--bwlimit", timetable)
+		}
+	}
+
+	return params
+}
+
+func scheduleWindows(cfg *config.UploaderCheck) (*timeutils.Windows, *time.Location, error) {
+	loc := time.Local
+	if cfg.Timezone != "" {
+		l, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+		}
+
+		loc = l
+	}
+
+	windows, err := timeutils.ParseWindows(cfg.Windows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return windows, loc, nil
+}
+
+// scheduleTimetable returns an rclone --bwlimit timetable lifting the limit while the windows are open,
+// in the local time rclone uses, so a run continuing past the end of a window is throttled.
+func scheduleTimetable(cfg *config.UploaderCheck) (string, error) {
+	windows, loc, err := scheduleWindows(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	transitions := windows.Transitions()
+	if len(transitions) == 0 {
+		// always or never open
+		return "", nil
+	}
+
+	type entry struct {
+		minute int
+		value  string
+	}
+
+	// minutes of this week in the configured timezone
+	now := time.Now().In(loc)
+	sunday := now.AddDate(0, 0, -int(now.Weekday()))
+
+	entries := make([]entry, 0, len(transitions))
+	for _, t := range transitions {
+		at := time.Date(sunday.Year(), sunday.Month(), sunday.Day(), 0, t.Minute, 0, 0, loc).Local()
+
+		value := cfg.BwLimit
+		if t.Open {
+			value = "off"
+		}
+
+		entries = append(entries, entry{
+			minute: int(at.Weekday())*24*60 + at.Hour()*60 + at.Minute(),
+			value:  fmt.Sprintf("%s-%02d:%02d,%s", at.Weekday().String()[:3], at.Hour(), at.Minute(), value),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].minute < entries[j].minute
+	})
+
+	values := make([]string, 0, len(entries))
+	for _, e := range entries {
+		values = append(values, e.value)
+	}
+
+	return strings.Join(values, " "), nil
+}
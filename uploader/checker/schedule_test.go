@@ -0,0 +1,137 @@
+package checker
+
+import (
+	"github.com/l3uddz/crop/config"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleTimetable(t *testing.T) {
+	tests := []struct {
+		name     string
+		local    *time.Location
+		timezone string
+		windows  []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:    "local time",
+			local:   time.UTC,
+			windows: []string{"01:00-07:00 Mon-Wed"},
+			want: "Mon-01:00,off Mon-07:00,1M Tue-01:00,off Tue-07:00,1M " +
+				"Wed-01:00,off Wed-07:00,1M",
+		},
+		{
+			name:    "crossing midnight",
+			local:   time.UTC,
+			windows: []string{"22:00-02:00 Fri"},
+			want:    "Fri-22:00,off Sat-02:00,1M",
+		},
+		{
+			name:    "crossing the end of the week",
+			local:   time.UTC,
+			windows: []string{"22:00-02:00 Sat"},
+			want:    "Sun-02:00,1M Sat-22:00,off",
+		},
+		{
+			name:     "timezone ahead of local time",
+			local:    time.FixedZone("UTC-5", -5*60*60),
+			timezone: "UTC",
+			windows:  []string{"Mon"},
+			want:     "Sun-19:00,off Mon-19:00,1M",
+		},
+		{
+			name:     "timezone behind local time",
+			local:    time.FixedZone("UTC+2", 2*60*60),
+			timezone: "UTC",
+			windows:  []string{"22:00-02:00 Sat"},
+			want:     "Sun-00:00,off Sun-04:00,1M",
+		},
+		{
+			name:    "always open",
+			local:   time.UTC,
+			windows: []string{"00:00-24:00"},
+			want:    "",
+		},
+		{
+			name:    "never open",
+			local:   time.UTC,
+			windows: nil,
+			want:    "",
+		},
+		{
+			name:     "invalid timezone",
+			local:    time.UTC,
+			timezone: "Nowhere/Special",
+			windows:  []string{"Mon"},
+			wantErr:  true,
+		},
+		{
+			name:    "invalid window",
+			local:   time.UTC,
+			windows: []string{"Mon-"},
+			wantErr: true,
+		},
+	}
+
+	local := time.Local
+	t.Cleanup(func() {
+		time.Local = local
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			time.Local = tt.local
+
+			got, err := scheduleTimetable(scheduleConfig(tt.timezone, tt.windows, "1M"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scheduleTimetable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("scheduleTimetable() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleRcloneParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []string
+		bwLimit string
+		want    []string
+	}{
+		{"bwlimit", []string{"22:00-02:00 Fri"}, "1M", []string{"--bwlimit", "Fri-22:00,off Sat-02:00,1M"}},
+		{"without bwlimit", []string{"22:00-02:00 Fri"}, "", nil},
+		{"always open", []string{"00:00-24:00"}, "1M", nil},
+	}
+
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() {
+		time.Local = local
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Schedule{}.RcloneParams(scheduleConfig("", tt.windows, tt.bwLimit), testLog(), nil)
+			if !reflect.DeepEqual(got.Flags, tt.want) {
+				t.Errorf("RcloneParams().Flags = %q, want %q", got.Flags, tt.want)
+			}
+		})
+	}
+}
+
+func scheduleConfig(timezone string, windows []string, bwLimit string) *config.UploaderCheck {
+	return &config.UploaderCheck{
+		UploaderCheckRule: config.UploaderCheckRule{
+			Type:     "schedule",
+			Windows:  windows,
+			Timezone: timezone,
+			BwLimit:  bwLimit,
+		},
+	}
+}
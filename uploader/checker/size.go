@@ -21,18 +21,21 @@ func (Size) Check(cfg *config.UploaderCheck, log *logrus.Entry, paths []pathutil
 		}).Info("Size is greater than specified limit")
 
 		return &Result{
-			Passed: true,
-			Info:   s,
+			Passed:   true,
+			AllFiles: true,
+			Info:     s,
 		}, nil
 	}
 
 	return &Result{
-		Passed: false,
-		Info:   humanize.IBytes(cfg.Limit - size),
+		Passed:   false,
+		AllFiles: true,
+		Info:     humanize.IBytes(cfg.Limit - size),
 	}, nil
 }
 
-func (Size) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, path pathutils.Path, size uint64) (bool, error) {
+func (Size) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, res *Result, path pathutils.Path,
+	size uint64) (bool, error) {
 	// Check Total Size
 	if size > cfg.Limit {
 		return true, nil
@@ -41,6 +44,8 @@ func (Size) CheckFile(cfg *config.UploaderCheck, log *logrus.Entry, path pathuti
 	return false, nil
 }
 
-func (Size) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry) []string {
-	return rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude)
+func (Size) RcloneParams(cfg *config.UploaderCheck, log *logrus.Entry, res *Result) RcloneParams {
+	return RcloneParams{
+		Filters: rclone.IncludeExcludeToFilters(cfg.Include, cfg.Exclude),
+	}
 }
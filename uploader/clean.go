@@ -1,6 +1,7 @@
 package uploader
 
 import (
+	"context"
 	"github.com/l3uddz/crop/pathutils"
 
 	"github.com/l3uddz/crop/rclone"
@@ -9,7 +10,12 @@ import (
 	"strings"
 )
 
-func (u *Uploader) Clean(path *pathutils.Path) error {
+func (u *Uploader) Clean(ctx context.Context, path *pathutils.Path) error {
+	// do not start when cancelled
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// iterate all remotes and remove the file/folder
 	for _, remotePath := range u.Config.Remotes.Clean {
 		// transform remotePath to a path that can be removed
@@ -29,10 +35,10 @@ func (u *Uploader) Clean(path *pathutils.Path) error {
 		rLog.Debug("Removing...")
 		if path.IsDir {
 			// remove directory
-			success, exitCode, err = rclone.RmDir(cleanRemotePath)
+			success, exitCode, err = rclone.RmDir(ctx, cleanRemotePath)
 		} else {
 			// remove file
-			success, exitCode, err = rclone.DeleteFile(cleanRemotePath)
+			success, exitCode, err = rclone.DeleteFile(ctx, cleanRemotePath)
 		}
 
 		// handle response
@@ -49,6 +55,11 @@ func (u *Uploader) Clean(path *pathutils.Path) error {
 		}
 	}
 
+	// the remote removal(s) may not have finished
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// cleanup cleaned path locally
 	if !u.GlobalConfig.Rclone.DryRun && u.Config.Hidden.Cleanup {
 		if err := os.Remove(path.RealPath); err != nil {
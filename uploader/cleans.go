@@ -1,12 +1,13 @@
 package uploader
 
 import (
+	"context"
 	"github.com/pkg/errors"
 	"github.com/yale8848/gorpool"
 	"time"
 )
 
-func (u *Uploader) PerformCleans(gp *gorpool.Pool) error {
+func (u *Uploader) PerformCleans(ctx context.Context, gp *gorpool.Pool) error {
 	// refresh details about hidden files/folders to remove
 	if err := u.RefreshHiddenPaths(); err != nil {
 		u.Log.WithError(err).Error("Failed refreshing details of hidden files/folders to clean")
@@ -21,7 +22,7 @@ func (u *Uploader) PerformCleans(gp *gorpool.Pool) error {
 			p := path
 
 			gp.AddJob(func() {
-				_ = u.Clean(&p)
+				_ = u.Clean(ctx, &p)
 			})
 		}
 
@@ -38,7 +39,7 @@ func (u *Uploader) PerformCleans(gp *gorpool.Pool) error {
 			p := path
 
 			gp.AddJob(func() {
-				_ = u.Clean(&p)
+				_ = u.Clean(ctx, &p)
 			})
 		}
 
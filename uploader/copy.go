@@ -1,15 +1,16 @@
 package uploader
 
 import (
+	"context"
 	"fmt"
-	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/l3uddz/crop/stringutils"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
-func (u *Uploader) Copy(additionalRcloneParams []string) error {
+func (u *Uploader) Copy(ctx context.Context, additionalRcloneParams []string) error {
 	// set variables
 	extraParams := u.Config.RcloneParams.Copy
 	if additionalRcloneParams != nil {
@@ -24,6 +25,7 @@ func (u *Uploader) Copy(additionalRcloneParams []string) error {
 	for _, remotePath := range u.Config.Remotes.Copy {
 		// set variables
 		attempts := 1
+		backoffs := 0
 
 		// copy to remote
 		for {
@@ -51,44 +53,77 @@ func (u *Uploader) Copy(additionalRcloneParams []string) error {
 
 			// copy
 			rLog.Info("Copying...")
-			success, exitCode, err := rclone.Copy(u.Config.LocalFolder, remotePath, serviceAccounts, extraParams)
+			result, err := rclone.Copy(ctx, u.Config.LocalFolder, remotePath, serviceAccounts, extraParams)
+			u.Stats.Add(result.Stats)
 
 			// check result
 			if err != nil {
 				rLog.WithError(err).Errorf("Failed unexpectedly...")
-				return errors.WithMessagef(err, "copy failed unexpectedly with exit code: %v", exitCode)
-			} else if success {
+				return errors.WithMessagef(err, "copy failed unexpectedly with exit code: %v", result.ExitCode)
+			} else if result.Success {
 				// successful exit code
 				break
 			}
 
-			// is this an exit code we can retry?
-			switch exitCode {
-			case rclone.ExitFatalError:
+			// determine why the copy failed
+			rLog = rLog.WithFields(logrus.Fields{
+				"exit_code": result.ExitCode,
+				"failure":   result.Failure,
+			})
+
+			// is this a failure we can recover from?
+			switch result.Failure.Action() {
+			case rclone.ActionRotate:
 				// are we using service accounts?
 				if len(serviceAccounts) == 0 {
 					// we are not using service accounts, so mark this remote as banned
-					if err := cache.SetBanned(stringutils.FromLeftUntil(remotePath, ":"), 25); err != nil {
+					if err := rclone.BanRemote(stringutils.FromLeftUntil(remotePath, ":")); err != nil {
 						rLog.WithError(err).Errorf("Failed banning remote")
 					}
 
-					return fmt.Errorf("copy failed with exit code: %v", exitCode)
+					return fmt.Errorf("copy failed with exit code: %v (%v)", result.ExitCode, result.Failure)
 				}
 
 				// ban service account(s) used
 				for _, sa := range serviceAccounts {
-					if err := cache.SetBanned(sa.ServiceAccountPath, 25); err != nil {
+					if err := rclone.BanServiceAccount(sa.ServiceAccountPath); err != nil {
 						rLog.WithError(err).Error("Failed banning service account, cannot try again...")
 						return fmt.Errorf("failed banning service account: %v", sa.ServiceAccountPath)
 					}
 				}
 
 				// attempt copy again
-				rLog.Warnf("Copy failed with retryable exit code %v, trying again...", exitCode)
+				rLog.Warnf("Copy failed with retryable failure %v, trying again...", result.Failure)
+				attempts++
+				continue
+			case rclone.ActionBackoff:
+				if backoffs >= rclone.MaxBackoffAttempts {
+					return fmt.Errorf("copy failed with exit code: %v (%v) after %d backoff(s)", result.ExitCode,
+						result.Failure, backoffs)
+				}
+
+				// wait before attempting copy again
+				wait := rclone.BackoffDuration(backoffs)
+				rLog.Warnf("Copy failed with retryable failure %v, trying again in %v...", result.Failure, wait)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+
+				backoffs++
 				attempts++
 				continue
+			case rclone.ActionBanRemote:
+				// this remote cannot be used until its quota resets
+				if err := rclone.BanRemote(stringutils.FromLeftUntil(remotePath, ":")); err != nil {
+					rLog.WithError(err).Errorf("Failed banning remote")
+				}
+
+				return fmt.Errorf("copy failed with exit code: %v (%v)", result.ExitCode, result.Failure)
 			default:
-				return fmt.Errorf("failed and cannot proceed with exit code: %v", exitCode)
+				return fmt.Errorf("failed and cannot proceed with exit code: %v (%v)", result.ExitCode,
+					result.Failure)
 			}
 		}
 	}
@@ -1,12 +1,19 @@
 package uploader
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"github.com/dustin/go-humanize"
+	"github.com/l3uddz/crop/metrics"
 	"github.com/l3uddz/crop/pathutils"
 	"github.com/l3uddz/crop/uploader/cleaner"
 	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"sort"
 	"strings"
+	"time"
 )
 
 var (
@@ -15,7 +22,17 @@ var (
 	}
 )
 
-func (u *Uploader) RefreshLocalFiles() error {
+func SupportedCleanerTypes() []string {
+	types := make([]string, 0, len(supportedCleaners))
+	for t := range supportedCleaners {
+		types = append(types, t)
+	}
+
+	sort.Strings(types)
+	return types
+}
+
+func (u *Uploader) RefreshLocalFiles(ctx context.Context) error {
 	// retrieve files
 	u.LocalFiles, u.LocalFilesSize = pathutils.GetPathsInFolder(u.Config.LocalFolder, true, false,
 		func(path string) *string {
@@ -47,16 +64,64 @@ func (u *Uploader) RefreshLocalFiles() error {
 			return &path
 		})
 
+	// skip files still being written
+	if err := u.skipInProgress(ctx); err != nil {
+		return err
+	}
+
+	// record results
+	var oldest time.Time
+	for _, p := range u.LocalFiles {
+		if oldest.IsZero() || p.ModifiedTime.Before(oldest) {
+			oldest = p.ModifiedTime
+		}
+	}
+
+	metrics.ObserveLocalFiles(u.Name, len(u.LocalFiles), u.LocalFilesSize, oldest)
+
 	// log results
 	u.Log.WithFields(logrus.Fields{
-		"found_files":  len(u.LocalFiles),
-		"files_size":   humanize.IBytes(u.LocalFilesSize),
-		"local_folder": u.Config.LocalFolder,
+		"found_files":   len(u.LocalFiles),
+		"skipped_files": len(u.SkippedFiles),
+		"files_size":    humanize.IBytes(u.LocalFilesSize),
+		"local_folder":  u.Config.LocalFolder,
 	}).Info("Refreshed local files")
 
 	return nil
 }
 
+// writeTempList writes lines to a temporary file, which is removed by the returned func.
+func (u *Uploader) writeTempList(pattern string, lines []string) (string, func(), error) {
+	f, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed creating list: %w", err)
+	}
+
+	cleanup := func() {
+		if err := os.Remove(f.Name()); err != nil {
+			u.Log.WithError(err).Warnf("Failed removing list: %q", f.Name())
+		}
+	}
+
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		_, _ = w.WriteString(line + "\n")
+	}
+
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed writing list %q: %w", f.Name(), err)
+	}
+
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed writing list %q: %w", f.Name(), err)
+	}
+
+	return f.Name(), cleanup, nil
+}
+
 func (u *Uploader) RefreshHiddenPaths() error {
 	var err error
 
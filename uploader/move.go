@@ -1,15 +1,16 @@
 package uploader
 
 import (
+	"context"
 	"fmt"
-	"github.com/l3uddz/crop/cache"
 	"github.com/l3uddz/crop/rclone"
 	"github.com/l3uddz/crop/stringutils"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 
-func (u *Uploader) Move(serverSide bool, additionalRcloneParams []string) error {
+func (u *Uploader) Move(ctx context.Context, serverSide bool, additionalRcloneParams []string) error {
 	var moveRemotes []rclone.RemoteInstruction
 	var extraParams []string
 
@@ -51,6 +52,7 @@ func (u *Uploader) Move(serverSide bool, additionalRcloneParams []string) error
 	for _, move := range moveRemotes {
 		// set variables
 		attempts := 1
+		backoffs := 0
 
 		// move to remote
 		for {
@@ -83,52 +85,86 @@ func (u *Uploader) Move(serverSide bool, additionalRcloneParams []string) error
 
 			// move
 			rLog.Info("Moving...")
-			success, exitCode, err := rclone.Move(move.From, move.To, serviceAccounts, serverSide, extraParams)
+			result, err := rclone.Move(ctx, move.From, move.To, serviceAccounts, serverSide, extraParams)
+			u.Stats.Add(result.Stats)
 
 			// check result
 			if err != nil {
 				rLog.WithError(err).Errorf("Failed unexpectedly...")
-				return errors.WithMessagef(err, "move failed unexpectedly with exit code: %v", exitCode)
+				return errors.WithMessagef(err, "move failed unexpectedly with exit code: %v", result.ExitCode)
 			}
 
-			if success {
+			if result.Success {
 				// successful exit code
 				break
-			} else if serverSide {
+			}
+
+			// determine why the move failed
+			rLog = rLog.WithFields(logrus.Fields{
+				"exit_code": result.ExitCode,
+				"failure":   result.Failure,
+			})
+
+			// is this a failure we can recover from?
+			action := result.Failure.Action()
+			if serverSide && action != rclone.ActionBackoff {
 				// server side moves will not use service accounts, so we will not retry...
-				return fmt.Errorf("failed and cannot proceed with exit code: %v", exitCode)
+				return fmt.Errorf("failed and cannot proceed with exit code: %v (%v)", result.ExitCode,
+					result.Failure)
 			}
 
-			// is this an exit code we can retry?
-			switch exitCode {
-			case rclone.ExitFatalError:
+			switch action {
+			case rclone.ActionRotate:
 				// are we using service accounts?
 				if len(serviceAccounts) == 0 {
-					// we are not using service accounts, so mark this remote as banned (if non server side move)
-					if !serverSide {
-						// this was not a server side move, so lets ban the remote we are moving too
-						if err := cache.SetBanned(stringutils.FromLeftUntil(move.To, ":"), 25); err != nil {
-							rLog.WithError(err).Errorf("Failed banning remote")
-						}
+					// we are not using service accounts, so mark the remote we are moving too as banned
+					if err := rclone.BanRemote(stringutils.FromLeftUntil(move.To, ":")); err != nil {
+						rLog.WithError(err).Errorf("Failed banning remote")
 					}
 
-					return fmt.Errorf("move failed with exit code: %v", exitCode)
+					return fmt.Errorf("move failed with exit code: %v (%v)", result.ExitCode, result.Failure)
 				}
 
 				// ban the service account(s) used
 				for _, sa := range serviceAccounts {
-					if err := cache.SetBanned(sa.ServiceAccountPath, 25); err != nil {
+					if err := rclone.BanServiceAccount(sa.ServiceAccountPath); err != nil {
 						rLog.WithError(err).Error("Failed banning service account, cannot try again...")
 						return fmt.Errorf("failed banning service account: %v", sa.ServiceAccountPath)
 					}
 				}
 
 				// attempt move again
-				rLog.Warnf("Move failed with retryable exit code %v, trying again...", exitCode)
+				rLog.Warnf("Move failed with retryable failure %v, trying again...", result.Failure)
+				attempts++
+				continue
+			case rclone.ActionBackoff:
+				if backoffs >= rclone.MaxBackoffAttempts {
+					return fmt.Errorf("move failed with exit code: %v (%v) after %d backoff(s)", result.ExitCode,
+						result.Failure, backoffs)
+				}
+
+				// wait before attempting move again
+				wait := rclone.BackoffDuration(backoffs)
+				rLog.Warnf("Move failed with retryable failure %v, trying again in %v...", result.Failure, wait)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+
+				backoffs++
 				attempts++
 				continue
+			case rclone.ActionBanRemote:
+				// the remote we are moving too cannot be used until its quota resets
+				if err := rclone.BanRemote(stringutils.FromLeftUntil(move.To, ":")); err != nil {
+					rLog.WithError(err).Errorf("Failed banning remote")
+				}
+
+				return fmt.Errorf("move failed with exit code: %v (%v)", result.ExitCode, result.Failure)
 			default:
-				return fmt.Errorf("failed and cannot proceed with exit code: %v", exitCode)
+				return fmt.Errorf("failed and cannot proceed with exit code: %v (%v)", result.ExitCode,
+					result.Failure)
 			}
 		}
 	}
@@ -0,0 +1,168 @@
+package uploader
+
+import (
+	"context"
+	"github.com/l3uddz/crop/pathutils"
+	"github.com/sirupsen/logrus"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSampleInterval = 5 * time.Second
+)
+
+var (
+	defaultPartialSuffixes = []string{".part", ".partial", ".!qB", ".!ut", ".crdownload"}
+)
+
+// SkipRcloneParams writes the skipped files to a temporary --exclude-from list, which is removed by the returned
+// func. rclone applies it before any --filter, so it is not overridden by the check's includes.
+func (u *Uploader) SkipRcloneParams() ([]string, func(), error) {
+	if len(u.SkippedFiles) == 0 {
+		return nil, func() {}, nil
+	}
+
+	lines := make([]string, 0, len(u.SkippedFiles))
+	for _, path := range u.SkippedFiles {
+		lines = append(lines, "/"+escapeFilterPattern(path.RelativeRealPath))
+	}
+
+	name, cleanup, err := u.writeTempList("crop_exclude_from_*.txt", lines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []string{"--exclude-from", name}, cleanup, nil
+}
+
+// skipInProgress removes files that are still being written from the local files.
+func (u *Uploader) skipInProgress(ctx context.Context) error {
+	u.SkippedFiles = nil
+	if u.Config.InProgress.Disabled || len(u.LocalFiles) == 0 {
+		return nil
+	}
+
+	suffixes := u.Config.InProgress.Suffixes
+	if len(suffixes) == 0 {
+		suffixes = defaultPartialSuffixes
+	}
+
+	open := pathutils.GetFilesOpenForWriting(u.Config.LocalFolder)
+
+	reasons := make(map[string]string)
+	candidates := make([]pathutils.Path, 0, len(u.LocalFiles))
+
+	for _, path := range u.LocalFiles {
+		switch {
+		case path.IsDir:
+			continue
+		case hasSuffix(path.FileName, suffixes):
+			reasons[path.RealPath] = "partial download"
+		case open[path.RelativeRealPath]:
+			reasons[path.RealPath] = "open for writing"
+		default:
+			candidates = append(candidates, path)
+		}
+	}
+
+	// only files modified within the interval may still be written to, sample those again after it
+	recent := modifiedSince(candidates, time.Now().Add(-u.sampleInterval))
+	if u.sampleInterval > 0 && len(recent) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(u.sampleInterval):
+		}
+
+		for _, path := range changedFiles(recent) {
+			reasons[path.RealPath] = "size changed"
+		}
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	files := make([]pathutils.Path, 0, len(u.LocalFiles))
+	var size uint64
+
+	for _, path := range u.LocalFiles {
+		if reason, ok := reasons[path.RealPath]; ok {
+			u.Log.WithFields(logrus.Fields{
+				"file_path": path.RelativeRealPath,
+				"reason":    reason,
+			}).Info("Skipping file in progress")
+
+			u.SkippedFiles = append(u.SkippedFiles, path)
+			continue
+		}
+
+		files = append(files, path)
+		if !path.IsDir {
+			size += uint64(path.Size)
+		}
+	}
+
+	u.LocalFiles = files
+	u.LocalFilesSize = size
+	return nil
+}
+
+func modifiedSince(paths []pathutils.Path, since time.Time) []pathutils.Path {
+	modified := make([]pathutils.Path, 0)
+
+	for _, path := range paths {
+		if path.ModifiedTime.After(since) {
+			modified = append(modified, path)
+		}
+	}
+
+	return modified
+}
+
+func changedFiles(paths []pathutils.Path) []pathutils.Path {
+	changed := make([]pathutils.Path, 0)
+
+	for _, path := range paths {
+		fi, err := os.Stat(path.RealPath)
+		if err != nil {
+			// removed or moved, the file is not complete
+			changed = append(changed, path)
+			continue
+		}
+
+		if fi.Size() != path.Size || !fi.ModTime().Equal(path.ModifiedTime) {
+			changed = append(changed, path)
+		}
+	}
+
+	return changed
+}
+
+func hasSuffix(name string, suffixes []string) bool {
+	name = strings.ToLower(name)
+
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, strings.ToLower(suffix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// escapeFilterPattern escapes the characters rclone's filter patterns treat specially.
+func escapeFilterPattern(path string) string {
+	var sb strings.Builder
+
+	for _, r := range path {
+		if strings.ContainsRune(`\*?[]{}`, r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
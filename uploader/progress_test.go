@@ -0,0 +1,190 @@
+package uploader
+
+import (
+	"context"
+	"github.com/l3uddz/crop/config"
+	"github.com/l3uddz/crop/pathutils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSkipInProgress(t *testing.T) {
+	tests := []struct {
+		name        string
+		inProgress  config.UploaderInProgress
+		wantFiles   []string
+		wantSkipped []string
+	}{
+		{"in progress files are skipped", config.UploaderInProgress{},
+			[]string{"Show", "Show/old.mkv", "Show/done.mkv", "Show/episode.mkv.tmp"},
+			[]string{"Show/growing.mkv", "Show/episode.mkv.part"}},
+		{"custom suffixes", config.UploaderInProgress{Suffixes: []string{".TMP"}},
+			[]string{"Show", "Show/old.mkv", "Show/done.mkv", "Show/episode.mkv.part"},
+			[]string{"Show/growing.mkv", "Show/episode.mkv.tmp"}},
+		{"disabled", config.UploaderInProgress{Disabled: true},
+			[]string{"Show", "Show/old.mkv", "Show/done.mkv", "Show/growing.mkv", "Show/episode.mkv.part",
+				"Show/episode.mkv.tmp"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			old := time.Now().Add(-time.Hour)
+
+			u := &Uploader{
+				Log:            testLog(),
+				Config:         &config.UploaderConfig{LocalFolder: folder, InProgress: tt.inProgress},
+				sampleInterval: 200 * time.Millisecond,
+			}
+
+			u.LocalFiles = []pathutils.Path{
+				testPath(t, folder, "Show", "", time.Time{}),
+				testPath(t, folder, "Show/old.mkv", "old", old),
+				testPath(t, folder, "Show/done.mkv", "done", time.Time{}),
+				testPath(t, folder, "Show/growing.mkv", "grow", time.Time{}),
+				testPath(t, folder, "Show/episode.mkv.part", "part", old),
+				testPath(t, folder, "Show/episode.mkv.tmp", "tmp", old),
+			}
+
+			// old files are not sampled, so only the recently modified file is seen growing
+			written := make(chan struct{})
+			go func() {
+				defer close(written)
+
+				time.Sleep(50 * time.Millisecond)
+				for _, name := range []string{"Show/old.mkv", "Show/growing.mkv"} {
+					appendFile(t, filepath.Join(folder, name), "ing")
+				}
+			}()
+
+			err := u.skipInProgress(context.Background())
+			<-written
+
+			if err != nil {
+				t.Fatalf("skipInProgress() error = %v", err)
+			}
+
+			if got := relativePaths(u.LocalFiles); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("LocalFiles = %v, want %v", got, tt.wantFiles)
+			}
+
+			if got := relativePaths(u.SkippedFiles); !reflect.DeepEqual(got, tt.wantSkipped) {
+				t.Errorf("SkippedFiles = %v, want %v", got, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestSkipInProgressCancelled(t *testing.T) {
+	folder := t.TempDir()
+
+	u := &Uploader{
+		Log:            testLog(),
+		Config:         &config.UploaderConfig{LocalFolder: folder},
+		LocalFiles:     []pathutils.Path{testPath(t, folder, "new.mkv", "new", time.Time{})},
+		sampleInterval: time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := u.skipInProgress(ctx); err != context.Canceled {
+		t.Errorf("skipInProgress() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSkipRcloneParams(t *testing.T) {
+	u := &Uploader{
+		Log: testLog(),
+		SkippedFiles: []pathutils.Path{
+			{RelativeRealPath: "Show/[1080p] episode{1}.mkv"},
+			{RelativeRealPath: `Show/what?*\.mkv`},
+		},
+	}
+
+	params, cleanup, err := u.SkipRcloneParams()
+	if err != nil {
+		t.Fatalf("SkipRcloneParams() error = %v", err)
+	}
+
+	if len(params) != 2 || params[0] != "--exclude-from" {
+		cleanup()
+		t.Fatalf("SkipRcloneParams() = %v, want --exclude-from <list>", params)
+	}
+
+	got := readLines(t, params[1])
+	cleanup()
+
+	want := []string{`/Show/\[1080p\] episode\{1\}.mkv`, `/Show/what\?\*\\.mkv`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("list = %q, want %q", got, want)
+	}
+}
+
+// testPath creates the file (or folder when content is empty) & returns its path, modified is used when set.
+func testPath(t *testing.T, folder string, name string, content string, modified time.Time) pathutils.Path {
+	t.Helper()
+
+	path := filepath.Join(folder, name)
+	if content == "" {
+		if err := os.MkdirAll(path, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	} else if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !modified.IsZero() {
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pathutils.Path{
+		Path:             path,
+		RealPath:         path,
+		RelativeRealPath: name,
+		FileName:         fi.Name(),
+		Directory:        filepath.Dir(path),
+		IsDir:            fi.IsDir(),
+		Size:             fi.Size(),
+		ModifiedTime:     fi.ModTime(),
+	}
+}
+
+func appendFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Error(err)
+	}
+}
+
+func relativePaths(paths []pathutils.Path) []string {
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		names = append(names, path.RelativeRealPath)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	return names
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"regexp"
 	"strings"
+	"time"
 )
 
 type Uploader struct {
@@ -33,23 +34,24 @@ type Uploader struct {
 
 	LocalFiles     []pathutils.Path
 	LocalFilesSize uint64
+	SkippedFiles   []pathutils.Path
 	HiddenFiles    []pathutils.Path
 	HiddenFolders  []pathutils.Path
 
+	Stats rclone.Stats
+
 	Ws *web.Server
+
+	// Private
+	sampleInterval time.Duration
 }
 
 func New(config *config.Configuration, uploaderConfig *config.UploaderConfig, uploaderName string) (*Uploader, error) {
 	// init uploader dependencies
 	// - checker
-	c, found := supportedCheckers[strings.ToLower(uploaderConfig.Check.Type)]
-	if !found {
-		return nil, fmt.Errorf("unknown check type specified: %q", uploaderConfig.Check.Type)
-	}
-
-	chk, ok := c.(checker.Interface)
-	if !ok {
-		return nil, fmt.Errorf("failed typecasting to checker interface for: %q", uploaderConfig.Check.Type)
+	chk, err := newChecker(uploaderConfig)
+	if err != nil {
+		return nil, err
 	}
 
 	// - cleaner
@@ -62,6 +64,7 @@ func New(config *config.Configuration, uploaderConfig *config.UploaderConfig, up
 		}
 
 		// Typecast found cleaner
+		var ok bool
 		cln, ok = c.(cleaner.Interface)
 		if !ok {
 			return nil, fmt.Errorf("failed typecasting to cleaner interface for: %q", uploaderConfig.Hidden.Type)
@@ -92,6 +95,17 @@ func New(config *config.Configuration, uploaderConfig *config.UploaderConfig, up
 		excludePatterns = append(excludePatterns, g)
 	}
 
+	// - in progress sample interval
+	sampleInterval := defaultSampleInterval
+	if si := uploaderConfig.InProgress.SampleInterval; si != "" {
+		d, err := time.ParseDuration(si)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid in_progress sample interval: %q", si)
+		}
+
+		sampleInterval = d
+	}
+
 	// - service account manager
 	sam := rclone.NewServiceAccountManager(config.Rclone.ServiceAccountRemotes, 1)
 
@@ -115,6 +129,7 @@ func New(config *config.Configuration, uploaderConfig *config.UploaderConfig, up
 		ExcludePatterns:           excludePatterns,
 		RemoteServiceAccountFiles: sam,
 		Ws:                        web.New("127.0.0.1", l, uploaderName, sam),
+		sampleInterval:            sampleInterval,
 	}
 
 	return uploader, nil
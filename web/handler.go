@@ -2,7 +2,8 @@ package web
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/l3uddz/crop/cache"
+	"github.com/l3uddz/crop/metrics"
+	"github.com/l3uddz/crop/rclone"
 	"time"
 )
 
@@ -46,7 +47,7 @@ func (ws *Server) ServiceAccountHandler(c *fiber.Ctx) error {
 	ws.log.Warnf("Service account limit reached for remote %q, sa: %v", req.Remote, req.OldServiceAccount)
 
 	// ban this service account
-	if err := cache.SetBanned(req.OldServiceAccount, 25); err != nil {
+	if err := rclone.BanServiceAccount(req.OldServiceAccount); err != nil {
 		ws.log.WithError(err).Error("Failed banning service account, cannot try again...")
 		return c.SendStatus(500)
 	}
@@ -79,6 +80,7 @@ func (ws *Server) ServiceAccountHandler(c *fiber.Ctx) error {
 	ws.saCache.cache[sa[0].ServiceAccountPath] = cacheEntry
 
 	// return service account
+	metrics.ServiceAccountRotated(req.Remote)
 	ws.log.Warnf("New service account for remote %q, sa: %v", req.Remote, sa[0].ServiceAccountPath)
 	return c.SendString(sa[0].ServiceAccountPath)
 }